@@ -1,81 +1,114 @@
 package main
 
 import (
-	"context"
-	"errors"
-	"net/http"
+	"flag"
+	"fmt"
 	"os"
-	"os/signal"
-	"time"
 
 	"github.com/C0deNe0/go-boiler/internal/config"
-	"github.com/C0deNe0/go-boiler/internal/database"
-	"github.com/C0deNe0/go-boiler/internal/handler"
-	"github.com/C0deNe0/go-boiler/internal/logger"
-	"github.com/C0deNe0/go-boiler/internal/repository"
-	"github.com/C0deNe0/go-boiler/internal/router"
-	"github.com/C0deNe0/go-boiler/internal/server"
-	"github.com/C0deNe0/go-boiler/internal/service"
+	"github.com/C0deNe0/go-boiler/internal/errs"
 )
 
 const DefaultContextTimeout = 30
 
-func main() {
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		panic("failed to load config:" + err.Error())
-	}
+const usage = `Usage: go-boilerplate [flags] [command]
+
+Commands:
+  all                     run the HTTP server and the job worker (default)
+  serve                   run the HTTP server only
+  worker                  run the background job worker only
+  migrate up|down|status  apply, roll back one or report database migrations
+  migrate to N            migrate up or down to version N
+                          (migrate --dry-run prints the SQL instead of running it)
+  routes                  print the registered HTTP routes
+
+Flags:
+`
+
+// app carries what every command shares: the loaded configuration.
+type app struct {
+	cfg         *config.Config
+	sources     *config.Sources
+	loadOptions config.LoadOptions
+}
 
-	//init newrelic loggerService
-	loggerService := logger.NewLoggerService(cfg.Observeability)
-	defer loggerService.Shutdown()
+// commands maps each subcommand to its entrypoint; args excludes global flags.
+var commands = map[string]func(a *app, args []string){
+	"all":     func(a *app, _ []string) { runServe(a, true) },
+	"serve":   func(a *app, _ []string) { runServe(a, false) },
+	"worker":  func(a *app, _ []string) { runWorker(a) },
+	"migrate": func(a *app, args []string) { runMigrate(a, args[1:]) },
+	"routes":  func(a *app, _ []string) { runRoutes(a) },
+}
 
-	log := logger.NewLoggerWithService(cfg.Observeability, loggerService)
-	if cfg.Primary.Env != "local" {
-		if err := database.Migrate(context.Background(), &log, cfg); err != nil {
-			log.Fatal().Err(err).Msg("failed to migrate database")
-		}
+func main() {
+	checkConfig := flag.Bool("check-config", false, "validate the configuration, print every problem and exit")
+	dumpConfig := flag.String("dump-config", "", "print the effective configuration with secrets redacted as json, yaml or toml and exit")
+	configSchema := flag.String("config-schema", "", "print every supported setting as a json schema or an env var table (json, env) and exit")
+	errorCatalog := flag.Bool("error-catalog", false, "print the error code catalog as json and exit")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	//init server
-	srv, err := server.New(cfg, &log, loggerService)
-	if err != nil {
-		log.Fatal().Err(err).Msg("failed to init server")
+	command := flag.Arg(0)
+	if command == "" {
+		command = "all"
 	}
-
-	//init repo , handler , service
-
-	repos := repository.NewRepositories(srv)
-	services, serviceErr := service.NewServices(srv, repos)
-	if serviceErr != nil {
-		log.Fatal().Err(serviceErr).Msg("could not create services")
-
+	run, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		flag.Usage()
+		os.Exit(2)
 	}
-	handlers := handler.NewHandlers(srv, services)
-
-	//init router
-	r := router.NewRouter(srv, handlers, services)
 
-	//setup httpserver
-	srv.SetupHTTPServer(r)
-
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-
-	go func() {
-		if err = srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Fatal().Err(err).Msg("failed to start server")
+	// codes are registered by package initialization, so no config is needed
+	if *errorCatalog {
+		if err := errs.WriteCatalog(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
-	}()
+		return
+	}
 
-	<-ctx.Done()
-	ctx, cancel := context.WithTimeout(context.Background(), DefaultContextTimeout*time.Second)
+	loadOptions := config.DefaultLoadOptions()
 
-	if err = srv.ShutDown(ctx); err != nil {
-		log.Fatal().Err(err).Msg("server forced to shutdown")
+	if *configSchema != "" {
+		defaults, err := config.Defaults(loadOptions)
+		if err == nil {
+			err = config.WriteSchema(os.Stdout, defaults, *configSchema)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	stop()
-	cancel()
+	cfg, sources, err := config.Load(loadOptions)
+	if *checkConfig {
+		config.WriteReport(os.Stdout, sources, err)
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		config.WriteReport(os.Stderr, sources, err)
+		os.Exit(1)
+	}
+	if *dumpConfig != "" {
+		if err := config.WriteDump(os.Stdout, cfg, *dumpConfig); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	log.Info().Msg("server exited properly")
+	run(&app{
+		cfg:         cfg,
+		sources:     sources,
+		loadOptions: loadOptions,
+	}, flag.Args())
 }
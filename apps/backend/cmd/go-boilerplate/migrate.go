@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/C0deNe0/go-boiler/internal/database"
+	"github.com/C0deNe0/go-boiler/internal/logger"
+)
+
+const migrateUsage = `Usage: go-boilerplate migrate [--dry-run] up|down|status|to N
+
+`
+
+// runMigrate manages the database schema: up, down, status or to N.
+func runMigrate(a *app, args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "print the SQL that would run without executing it")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), migrateUsage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	args = flags.Args()
+
+	if len(args) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	log := logger.NewLoggerWithConfig(a.cfg.Observeability)
+	ctx := context.Background()
+
+	m, err := database.NewMigrator(ctx, &log, a.cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to init migrator")
+	}
+	defer m.Close(ctx)
+
+	var target int32
+	switch args[0] {
+	case "status":
+		if err := printMigrationStatus(ctx, m); err != nil {
+			log.Fatal().Err(err).Msg("failed to read migration status")
+		}
+		return
+	case "up":
+		target = m.LatestVersion()
+	case "down":
+		target, err = m.DownTarget(ctx)
+	case "to":
+		if len(args) < 2 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		var parsed int64
+		parsed, err = strconv.ParseInt(args[1], 10, 32)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid target version %q\n", args[1])
+			os.Exit(2)
+		}
+		target = int32(parsed)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate subcommand %q\n\n", args[0])
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Str("subcommand", args[0]).Msg("migration failed")
+	}
+
+	if *dryRun {
+		steps, err := m.Plan(ctx, target)
+		if err != nil {
+			log.Fatal().Err(err).Str("subcommand", args[0]).Msg("could not plan migration")
+		}
+		printMigrationPlan(steps)
+		return
+	}
+
+	if err := m.To(ctx, target); err != nil {
+		log.Fatal().Err(err).Str("subcommand", args[0]).Msg("migration failed")
+	}
+}
+
+func printMigrationStatus(ctx context.Context, m *database.Migrator) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("current version: %d\nlatest version:  %d\n", status.Current, status.Latest)
+	if status.Current > status.Latest {
+		fmt.Println("database schema is ahead of the embedded migrations")
+	}
+
+	fmt.Println("migrations:")
+	for _, migration := range status.Migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+		fmt.Printf("  %3d  %-8s %s\n", migration.Sequence, state, migration.Name)
+	}
+	return nil
+}
+
+func printMigrationPlan(steps []database.MigrationStep) {
+	if len(steps) == 0 {
+		fmt.Println("-- nothing to migrate")
+		return
+	}
+
+	for _, step := range steps {
+		fmt.Printf("-- %s %d: %s\n%s\n\n", step.Direction, step.Sequence, step.Name, step.SQL)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/C0deNe0/go-boiler/internal/handler"
+	"github.com/C0deNe0/go-boiler/internal/logger"
+	"github.com/C0deNe0/go-boiler/internal/repository"
+	"github.com/C0deNe0/go-boiler/internal/router"
+	"github.com/C0deNe0/go-boiler/internal/server"
+	"github.com/C0deNe0/go-boiler/internal/service"
+)
+
+// runRoutes builds the router without connecting to any backing service and prints its routes.
+func runRoutes(a *app) {
+	log := logger.NewLoggerWithConfig(a.cfg.Observeability)
+
+	srv := server.NewBase(a.cfg, &log, nil)
+
+	repos := repository.NewRepositories(srv)
+	services, err := service.NewServices(srv, repos)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create services")
+	}
+	handlers := handler.NewHandlers(srv, services)
+
+	routes := router.NewRouter(srv, handlers, services).Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER")
+	for _, route := range routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", route.Method, route.Path, route.Name)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/config"
+	"github.com/C0deNe0/go-boiler/internal/database"
+	"github.com/C0deNe0/go-boiler/internal/handler"
+	"github.com/C0deNe0/go-boiler/internal/logger"
+	"github.com/C0deNe0/go-boiler/internal/repository"
+	"github.com/C0deNe0/go-boiler/internal/router"
+	"github.com/C0deNe0/go-boiler/internal/server"
+	"github.com/C0deNe0/go-boiler/internal/service"
+	"github.com/rs/zerolog"
+)
+
+// runServe starts the HTTP server and, with withWorker, the job worker in the same process.
+func runServe(a *app, withWorker bool) {
+	cfg := a.cfg
+
+	//init newrelic loggerService
+	loggerService := logger.NewLoggerService(cfg.Observeability)
+	defer loggerService.Shutdown()
+
+	log := logger.NewLoggerWithService(cfg.Observeability, loggerService)
+	log.Info().Strs("layers", a.sources.Layers).Msg("loaded config layers")
+
+	//init server; migrations are applied by the migrate command
+	srv, err := server.New(cfg, &log, loggerService, withWorker)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to init server")
+	}
+
+	if err := srv.Db.CheckSchemaVersion(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("refusing to start against this database schema")
+	}
+
+	//start job server
+	if withWorker {
+		if err := srv.Job.Start(); err != nil {
+			log.Fatal().Err(err).Msg("failed to start job server")
+		}
+		srv.Outbox.StartRelay()
+		srv.Leader.Start()
+	}
+
+	srv.ConfigWatcher = newConfigWatcher(a, &log, loggerService, srv.Db)
+
+	//init repo , handler , service
+
+	repos := repository.NewRepositories(srv)
+	services, serviceErr := service.NewServices(srv, repos)
+	if serviceErr != nil {
+		log.Fatal().Err(serviceErr).Msg("could not create services")
+
+	}
+	handlers := handler.NewHandlers(srv, services)
+
+	//init router
+	r := router.NewRouter(srv, handlers, services)
+
+	//setup httpserver
+	srv.SetupHTTPServer(r)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	srv.ConfigWatcher.Start(ctx)
+
+	go func() {
+		if err = srv.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal().Err(err).Msg("failed to start server")
+		}
+	}()
+
+	<-ctx.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultContextTimeout*time.Second)
+
+	if err = srv.ShutDown(ctx); err != nil {
+		log.Fatal().Err(err).Msg("server forced to shutdown")
+	}
+
+	stop()
+	cancel()
+
+	log.Info().Msg("server exited properly")
+}
+
+// newConfigWatcher reloads runtime settings on SIGHUP or config file changes.
+func newConfigWatcher(a *app, log *zerolog.Logger, loggerService *logger.LoggerService, db *database.Database) *config.Watcher {
+	watcher := config.NewWatcher(a.loadOptions, a.cfg, a.sources, log)
+	watcher.Subscribe(func(_, current *config.Config) {
+		loggerService.SetLevel(current.Observeability.GetLogLevel())
+		db.SetReplicaHealthCheckInterval(current.Database.ReplicaHealthCheckInterval)
+	})
+	return watcher
+}
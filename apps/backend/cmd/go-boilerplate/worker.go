@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/logger"
+	"github.com/C0deNe0/go-boiler/internal/server"
+)
+
+// runWorker consumes background jobs and relays the job outbox without serving HTTP.
+func runWorker(a *app) {
+	cfg := a.cfg
+
+	loggerService := logger.NewLoggerService(cfg.Observeability)
+	defer loggerService.Shutdown()
+
+	log := logger.NewLoggerWithService(cfg.Observeability, loggerService)
+	log.Info().Strs("layers", a.sources.Layers).Msg("loaded config layers")
+
+	srv := server.NewBase(cfg, &log, loggerService)
+	if err := srv.ConnectDatabase(); err != nil {
+		log.Fatal().Err(err).Msg("failed to connect to database")
+	}
+
+	// the relay and the election need the outbox table and the jobs the current schema
+	if err := srv.Db.CheckSchemaVersion(context.Background()); err != nil {
+		log.Fatal().Err(err).Msg("refusing to start against this database schema")
+	}
+	srv.InitJobs()
+
+	if err := srv.Job.Start(); err != nil {
+		log.Fatal().Err(err).Msg("failed to start job server")
+	}
+	srv.Outbox.StartRelay()
+	srv.Leader.Start()
+
+	srv.ConfigWatcher = newConfigWatcher(a, &log, loggerService, srv.Db)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	srv.ConfigWatcher.Start(ctx)
+
+	<-ctx.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultContextTimeout*time.Second)
+
+	if err := srv.ShutDown(ctx); err != nil {
+		log.Fatal().Err(err).Msg("worker forced to shutdown")
+	}
+
+	stop()
+	cancel()
+
+	log.Info().Msg("worker exited properly")
+}
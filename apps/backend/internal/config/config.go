@@ -1,14 +1,9 @@
 package config
 
 import (
-	"os"
-	"strings"
+	"fmt"
 
-	"github.com/go-playground/validator/v10"
 	_ "github.com/joho/godotenv/autoload"
-	"github.com/knadh/koanf/providers/env"
-	"github.com/knadh/koanf/v2"
-	"github.com/rs/zerolog"
 )
 
 type Config struct {
@@ -26,25 +21,64 @@ type Primary struct {
 }
 
 type ServerConfig struct {
-	Port              string      `koanf:"port" validate:"required"`
-	ReadTimeout       int         `koanf:"read_timeout" validate:"required"`
-	WriteTimeout      int         `koanf:"write_timeout" validate:"required"`
-	IdleTimeout       int         `koanf:"idle_timeout" validate:"required"`
-	CORSAllowedOrigin []string    `koanf:"cors_allowed_origin" validate:"required"`
-	Redis             RedisConfig `koanf:"redis" validate:"required"`
+	Port              string          `koanf:"port" validate:"required"`
+	ReadTimeout       int             `koanf:"read_timeout" validate:"required"`
+	WriteTimeout      int             `koanf:"write_timeout" validate:"required"`
+	IdleTimeout       int             `koanf:"idle_timeout" validate:"required"`
+	CORSAllowedOrigin []string        `koanf:"cors_allowed_origin" validate:"required"`
+	Redis             RedisConfig     `koanf:"redis" validate:"required"`
+	RateLimit         RateLimitConfig `koanf:"rate_limit"`
+	// ErrorFormat is "json" for the errs.HTTPError shape or "problem" for
+	// RFC 9457 problem details; clients may ask for the latter by Accept header
+	ErrorFormat string `koanf:"error_format" validate:"omitempty,oneof=json problem"`
+	// ProblemTypeBaseURL prefixes problem types; "about:blank" is used when empty
+	ProblemTypeBaseURL string `koanf:"problem_type_base_url" validate:"omitempty,url"`
+}
+
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `koanf:"requests_per_second" validate:"min=0"`
+	Burst             int     `koanf:"burst" validate:"min=0"`
 }
 
 type DatabaseConfig struct {
 	Host            string `koanf:"host" validate:"required"`
 	Port            int    `koanf:"port"  validate:"required"`
 	User            string `koanf:"user"  validate:"required"`
-	Password        string `koanf:"password"`
+	Password        Secret `koanf:"password"`
 	Name            string `koanf:"name" validate:"required"`
 	SSLMode         string `koanf:"ssl_mode"  validate:"required"`
 	MaxOpenConns    int    `koanf:"max_open_conns"  validate:"required"`
 	MaxIdleConns    int    `koanf:"max_idle_conns" validate:"required"`
 	ConnMaxLifeTime int    `koanf:"conn_max_life_time" validate:"required"`
 	ConnMaxIdleTime int    `koanf:"conn_max_idle_time" validate:"required"`
+
+	// MinConns is the number of connections kept open even when idle. It is
+	// capped by MaxIdleConns, the only idle limit pgxpool has.
+	MinConns int `koanf:"min_conns" validate:"min=0"`
+	// HealthCheckPeriod is how often idle connections are checked, in seconds.
+	HealthCheckPeriod int `koanf:"health_check_period" validate:"min=0"`
+	// StatementTimeout aborts statements running longer than this many
+	// seconds; zero keeps the server default.
+	StatementTimeout int `koanf:"statement_timeout" validate:"min=0"`
+	// ApplicationName is reported in pg_stat_activity; defaults to the service name.
+	ApplicationName string `koanf:"application_name"`
+	// PoolStatsInterval is how often pool statistics are published, in seconds.
+	PoolStatsInterval int `koanf:"pool_stats_interval" validate:"min=0"`
+
+	// Replicas receive read-only work; the primary is used when none is healthy.
+	Replicas []ReplicaConfig `koanf:"replicas" validate:"dive"`
+	// ReplicaMaxLag is the replication lag in seconds above which a replica is
+	// skipped, and how long a request keeps reading from the primary after a write.
+	ReplicaMaxLag int `koanf:"replica_max_lag" validate:"min=0"`
+	// ReplicaHealthCheckInterval is how often replica health and lag are probed, in seconds.
+	ReplicaHealthCheckInterval int `koanf:"replica_health_check_interval" validate:"min=0"`
+}
+
+// ReplicaConfig points at a read replica that shares the primary's credentials.
+type ReplicaConfig struct {
+	Host string `koanf:"host" validate:"required"`
+	// Port defaults to the primary's port.
+	Port int `koanf:"port" validate:"min=0"`
 }
 
 type RedisConfig struct {
@@ -52,39 +86,42 @@ type RedisConfig struct {
 }
 
 type IntegrationConfig struct {
-	ResendAPIKey string `koanf:"resend_api_key" validate:"required"`
+	ResendAPIKey Secret `koanf:"resend_api_key" validate:"required"`
 }
 
 type AuthConfig struct {
-	SecretKey string `koanf:"secret_key" validate:"required"`
+	SecretKey Secret `koanf:"secret_key" validate:"required"`
+	// CursorSecret signs pagination cursors; when empty a key is derived from SecretKey.
+	CursorSecret Secret `koanf:"cursor_secret"`
 }
 
+// LoadConfig loads the configuration with DefaultLoadOptions.
 func LoadConfig() (*Config, error) {
-	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
-	k := koanf.New(".")
-
-	err := k.Load(env.Provider("BOILERPLATE_", ".", func(s string) string {
-		return strings.ToLower(strings.TrimPrefix(s, "BOILERPLATE_"))
-	}), nil)
+	cfg, _, err := Load(DefaultLoadOptions())
+	return cfg, err
+}
 
+// Load merges the config files in opts.Dir with BOILERPLATE_* environment
+// variables and reports which layer each value came from. Every validation
+// failure is collected into a ValidationErrors rather than stopping at the first.
+func Load(opts LoadOptions) (*Config, *Sources, error) {
+	k, sources, err := loadLayers(opts)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Could not load initial env variables")
+		return nil, nil, fmt.Errorf("could not load config layers: %w", err)
 	}
+
+	problems := resolveReferences(k, opts.SecretProvider)
+
 	mainConfig := &Config{}
 
 	err = k.Unmarshal("", mainConfig)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("could not unmarshall the main config")
+		return nil, sources, fmt.Errorf("could not unmarshall the main config: %w", err)
 	}
 
 	//now we validate the configs
 
-	validate := validator.New()
-
-	err = validate.Struct(mainConfig)
-	if err != nil {
-		logger.Fatal().Err(err).Msg("config validation failed")
-	}
+	problems = append(problems, validateStruct(mainConfig)...)
 
 	if mainConfig.Observeability == nil {
 		mainConfig.Observeability = DefaultObserveabilityConfig()
@@ -94,9 +131,11 @@ func LoadConfig() (*Config, error) {
 	mainConfig.Observeability.ServiceName = "boilerplate"
 	mainConfig.Observeability.Environment = mainConfig.Primary.Env
 
-	if err := mainConfig.Observeability.validate(); err != nil {
-		logger.Fatal().Err(err).Msg("observeability config validation failed")
+	problems = append(problems, mainConfig.Observeability.validate()...)
+
+	if len(problems) > 0 {
+		return nil, sources, problems.withSources(sources)
 	}
 
-	return mainConfig, nil
+	return mainConfig, sources, nil
 }
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a single invalid configuration value.
+type FieldError struct {
+	// Path is the koanf key of the value, e.g. "database.host".
+	Path string
+
+	// EnvVar is the environment variable that sets Path.
+	EnvVar string
+
+	// Source is the layer the offending value came from; empty when unset.
+	Source string
+
+	Message string
+}
+
+func (e FieldError) String() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors aggregates every configuration problem found by Load.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	problems := make([]string, 0, len(v))
+	for _, fe := range v {
+		problems = append(problems, fe.String())
+	}
+
+	return fmt.Sprintf("config validation failed: %s", strings.Join(problems, "; "))
+}
+
+// EnvVarName returns the environment variable that maps onto a koanf key.
+func EnvVarName(path string) string {
+	return EnvPrefix + strings.ToUpper(path)
+}
+
+func newFieldError(path, message string) FieldError {
+	return FieldError{
+		Path:    path,
+		EnvVar:  EnvVarName(path),
+		Message: message,
+	}
+}
+
+func (v ValidationErrors) withSources(sources *Sources) ValidationErrors {
+	for i := range v {
+		v[i].Source = sources.Of(v[i].Path)
+	}
+	return v
+}
+
+// newValidator names struct fields after their koanf tags so that
+// validation errors carry config paths rather than Go field names.
+func newValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("koanf"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	return validate
+}
+
+func validateStruct(cfg *Config) ValidationErrors {
+	err := newValidator().Struct(cfg)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return ValidationErrors{newFieldError("", err.Error())}
+	}
+
+	problems := make(ValidationErrors, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		// Namespace is "Config.<koanf path>"
+		path := fe.Namespace()
+		if i := strings.IndexByte(path, '.'); i >= 0 {
+			path = path[i+1:]
+		}
+
+		problems = append(problems, newFieldError(path, validationMessage(fe)))
+	}
+
+	return problems
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must not exceed %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	default:
+		if fe.Param() != "" {
+			return fmt.Sprintf("failed %s=%s", fe.Tag(), fe.Param())
+		}
+		return fmt.Sprintf("failed %s", fe.Tag())
+	}
+}
+
+// WriteReport prints the outcome of Load in a form meant for operators:
+// the applied layers followed by every problem and how to fix it.
+func WriteReport(w io.Writer, sources *Sources, err error) {
+	if sources != nil {
+		fmt.Fprintf(w, "config layers (lowest precedence first): %s\n", strings.Join(sources.Layers, ", "))
+	}
+
+	if err == nil {
+		fmt.Fprintln(w, "config is valid")
+		return
+	}
+
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		fmt.Fprintf(w, "config could not be loaded: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(w, "config is invalid (%d problems):\n", len(problems))
+	for _, fe := range problems {
+		origin := fe.EnvVar
+		if fe.Source != "" {
+			origin += ", from " + fe.Source
+		}
+		fmt.Fprintf(w, "  - %s (%s): %s\n", fe.Path, origin, fe.Message)
+	}
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/env"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+const (
+	// EnvPrefix is the prefix every environment variable read into the config must carry.
+	EnvPrefix = "BOILERPLATE_"
+
+	// ConfigDirEnv points the loader at a different directory of config files.
+	// It is consumed by the loader itself and never mapped onto a config key.
+	ConfigDirEnv = "BOILERPLATE_CONFIG_DIR"
+
+	// DefaultConfigDir is searched when ConfigDirEnv is not set.
+	DefaultConfigDir = "config"
+
+	// SourceEnv names the environment variable layer in Sources.
+	SourceEnv = "env"
+
+	baseConfigName   = "config"
+	sourceFilePrefix = "file:"
+)
+
+// loaderEnvVars configure the loader itself and are never mapped onto config keys.
+var loaderEnvVars = map[string]bool{
+	ConfigDirEnv:      true,
+	SecretProviderEnv: true,
+	SecretsDirEnv:     true,
+}
+
+// configFormats lists the supported file extensions in lookup order.
+var configFormats = []struct {
+	ext    string
+	parser koanf.Parser
+}{
+	{ext: ".yaml", parser: yaml.Parser()},
+	{ext: ".yml", parser: yaml.Parser()},
+	{ext: ".toml", parser: toml.Parser()},
+}
+
+// LoadOptions controls where the layered loader looks for configuration.
+type LoadOptions struct {
+	// Dir holds config.<ext> and config.<env>.<ext>; missing files are skipped.
+	Dir string
+
+	// Env selects the environment-specific file. When empty it is taken from
+	// primary.env, first from the environment and then from the base file.
+	Env string
+
+	// SecretProvider resolves secret:// references; file:// references are
+	// always read directly.
+	SecretProvider SecretProvider
+}
+
+// DefaultLoadOptions reads the config directory from ConfigDirEnv and
+// resolves secrets with DefaultSecretProvider.
+func DefaultLoadOptions() LoadOptions {
+	dir := os.Getenv(ConfigDirEnv)
+	if dir == "" {
+		dir = DefaultConfigDir
+	}
+
+	return LoadOptions{
+		Dir:            dir,
+		SecretProvider: DefaultSecretProvider(),
+	}
+}
+
+// Sources reports which layer supplied each configuration value.
+type Sources struct {
+	// Layers lists the applied layers, lowest precedence first.
+	Layers []string
+
+	// Values maps a koanf key (e.g. "database.host") to the layer that set it.
+	Values map[string]string
+}
+
+// Of returns the layer that supplied key, or an empty string when unset.
+func (s *Sources) Of(key string) string {
+	return s.Values[key]
+}
+
+// Keys returns every resolved key in sorted order.
+func (s *Sources) Keys() []string {
+	keys := make([]string, 0, len(s.Values))
+	for key := range s.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Files returns the paths of the config files that were applied.
+func (s *Sources) Files() []string {
+	var files []string
+	for _, layer := range s.Layers {
+		if path, ok := strings.CutPrefix(layer, sourceFilePrefix); ok {
+			files = append(files, path)
+		}
+	}
+
+	return files
+}
+
+func (s *Sources) apply(k *koanf.Koanf, name string, layer *koanf.Koanf) error {
+	if err := k.Merge(layer); err != nil {
+		return fmt.Errorf("merging %s: %w", name, err)
+	}
+
+	s.Layers = append(s.Layers, name)
+	for _, key := range layer.Keys() {
+		s.Values[key] = name
+	}
+
+	return nil
+}
+
+// loadLayers merges, in increasing precedence, the base config file, the
+// environment-specific config file and BOILERPLATE_* environment variables.
+func loadLayers(opts LoadOptions) (*koanf.Koanf, *Sources, error) {
+	k := koanf.New(".")
+	sources := &Sources{Values: make(map[string]string)}
+
+	base, basePath, err := loadFile(opts.Dir, baseConfigName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if base != nil {
+		if err := sources.apply(k, sourceFilePrefix+basePath, base); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	envVars, err := loadEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	environment := opts.Env
+	if environment == "" {
+		environment = envVars.String("primary.env")
+	}
+	if environment == "" {
+		environment = k.String("primary.env")
+	}
+
+	if environment != "" {
+		envFile, envPath, err := loadFile(opts.Dir, baseConfigName+"."+environment)
+		if err != nil {
+			return nil, nil, err
+		}
+		if envFile != nil {
+			if err := sources.apply(k, sourceFilePrefix+envPath, envFile); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if err := sources.apply(k, SourceEnv, envVars); err != nil {
+		return nil, nil, err
+	}
+
+	return k, sources, nil
+}
+
+// loadFile loads the first <dir>/<name><ext> that exists. It returns a nil
+// koanf instance when no file matches.
+func loadFile(dir, name string) (*koanf.Koanf, string, error) {
+	for _, format := range configFormats {
+		path := filepath.Join(dir, name+format.ext)
+
+		if _, err := os.Stat(path); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, "", fmt.Errorf("checking config file %s: %w", path, err)
+		}
+
+		k := koanf.New(".")
+		if err := k.Load(file.Provider(path), format.parser); err != nil {
+			return nil, "", fmt.Errorf("loading config file %s: %w", path, err)
+		}
+
+		return k, path, nil
+	}
+
+	return nil, "", nil
+}
+
+func loadEnv() (*koanf.Koanf, error) {
+	k := koanf.New(".")
+
+	err := k.Load(env.Provider(EnvPrefix, ".", func(s string) string {
+		if loaderEnvVars[s] {
+			return ""
+		}
+		return strings.ToLower(strings.TrimPrefix(s, EnvPrefix))
+	}), nil)
+	if err != nil {
+		return nil, fmt.Errorf("loading env variables: %w", err)
+	}
+
+	return k, nil
+}
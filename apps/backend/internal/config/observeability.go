@@ -17,10 +17,12 @@ type LoggingConfig struct {
 	Level             string        `koanf:"level" validate:"required"`
 	Format            string        `koanf:"format" validate:"required"`
 	SlowQueryTreshold time.Duration `koanf:"slow_query_treshold"`
+	// SlowQueryExplainRate is the fraction of slow queries whose plan is logged.
+	SlowQueryExplainRate float64 `koanf:"slow_query_explain_rate"`
 }
 
 type NewRelicConfig struct {
-	LicenseKey                string `koanf:"license_key" validate:"required"`
+	LicenseKey                Secret `koanf:"license_key" validate:"required"`
 	AppLogForwardEnabled      bool   `koanf:"app_log_forward_enabled"`
 	DistributedTracingEnabled bool   `koanf:"distributed_tracing_enabled"`
 	DebugLogging              bool   `koanf:"debug_logging"`
@@ -38,8 +40,9 @@ func DefaultObserveabilityConfig() *ObserveabilityConfig {
 		ServiceName: "boilerplate",
 		Environment: "development",
 		Logging: LoggingConfig{
-			Level:  "info",
-			Format: "json",
+			Level:             "info",
+			Format:            "json",
+			SlowQueryTreshold: 500 * time.Millisecond,
 		},
 		NewRelic: NewRelicConfig{
 			LicenseKey:                "",
@@ -56,12 +59,14 @@ func DefaultObserveabilityConfig() *ObserveabilityConfig {
 	}
 }
 
-func (c *ObserveabilityConfig) validate() error {
+func (c *ObserveabilityConfig) validate() ValidationErrors {
+	var problems ValidationErrors
+
 	if c.ServiceName == "" {
-		return fmt.Errorf("service_name is required")
+		problems = append(problems, newFieldError("observeability.service_name", "is required"))
 	}
 	if c.Environment == "" {
-		return fmt.Errorf("environment is required")
+		problems = append(problems, newFieldError("observeability.environment", "is required"))
 	}
 
 	//validte the logging config
@@ -73,16 +78,19 @@ func (c *ObserveabilityConfig) validate() error {
 		"error": true,
 	}
 	if !validLevels[c.Logging.Level] {
-		return fmt.Errorf("invalid logging level: %s(must be one of debug, info, warn, error)", c.Logging.Level)
-
+		problems = append(problems, newFieldError("observeability.logging.level",
+			fmt.Sprintf("invalid logging level: %s(must be one of debug, info, warn, error)", c.Logging.Level)))
 	}
 
 	//validate slow query treshold
 	if c.Logging.SlowQueryTreshold < 0 {
-		return fmt.Errorf("slow_query_treshold must be a positive duration")
+		problems = append(problems, newFieldError("observeability.logging.slow_query_treshold", "must be a positive duration"))
+	}
+	if c.Logging.SlowQueryExplainRate < 0 || c.Logging.SlowQueryExplainRate > 1 {
+		problems = append(problems, newFieldError("observeability.logging.slow_query_explain_rate", "must be between 0 and 1"))
 	}
 
-	return nil
+	return problems
 
 }
 
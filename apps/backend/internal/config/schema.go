@@ -0,0 +1,303 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/parsers/yaml"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	secretType   = reflect.TypeOf(Secret(""))
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// FieldSpec describes one configuration key as derived from the koanf and
+// validate struct tags of Config.
+type FieldSpec struct {
+	Path     string `json:"path"`
+	EnvVar   string `json:"env_var"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Secret   bool   `json:"secret"`
+	Default  any    `json:"default,omitempty"`
+	Rules    string `json:"rules,omitempty"`
+}
+
+// Defaults returns the configuration that applies before the
+// environment-specific file and environment variables are layered on top.
+func Defaults(opts LoadOptions) (*Config, error) {
+	cfg := &Config{Observeability: DefaultObserveabilityConfig()}
+
+	base, _, err := loadFile(opts.Dir, baseConfigName)
+	if err != nil {
+		return nil, err
+	}
+	if base != nil {
+		if err := base.Unmarshal("", cfg); err != nil {
+			return nil, fmt.Errorf("could not unmarshall the base config: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Describe lists every configuration key, objects before their children.
+// Defaults are taken from defaults when it is not nil.
+func Describe(defaults *Config) []FieldSpec {
+	if defaults == nil {
+		defaults = &Config{}
+	}
+
+	var specs []FieldSpec
+	describeStruct(reflect.ValueOf(defaults).Elem(), "", &specs)
+
+	return specs
+}
+
+func describeStruct(v reflect.Value, prefix string, specs *[]FieldSpec) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("koanf"), ",", 2)[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		rules := field.Tag.Get("validate")
+		value := v.Field(i)
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				value = reflect.Zero(field.Type.Elem())
+			} else {
+				value = value.Elem()
+			}
+		}
+
+		spec := FieldSpec{
+			Path:     path,
+			EnvVar:   EnvVarName(path),
+			Type:     typeName(value.Type()),
+			Required: slices.Contains(strings.Split(rules, ","), "required"),
+			Secret:   value.Type() == secretType,
+			Rules:    rules,
+		}
+
+		if spec.Type == "object" {
+			*specs = append(*specs, spec)
+			describeStruct(value, path, specs)
+			continue
+		}
+
+		if !value.IsZero() {
+			spec.Default = plainValue(value)
+		}
+		*specs = append(*specs, spec)
+	}
+}
+
+func typeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t.Kind() == reflect.Struct:
+		return "object"
+	case t.Kind() == reflect.Slice:
+		return "[]" + typeName(t.Elem())
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// plainValue converts a config value to what an operator would write in a
+// config file, redacting secrets.
+func plainValue(v reflect.Value) any {
+	switch {
+	case v.Type() == secretType:
+		return v.Interface().(Secret).String()
+	case v.Type() == durationType:
+		return v.Interface().(time.Duration).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return plainValue(v.Elem())
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		for i := range v.NumField() {
+			name := strings.SplitN(v.Type().Field(i).Tag.Get("koanf"), ",", 2)[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			out[name] = plainValue(v.Field(i))
+		}
+		return out
+	case reflect.Slice:
+		out := make([]any, 0, v.Len())
+		for i := range v.Len() {
+			out = append(out, plainValue(v.Index(i)))
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+// Dump returns cfg keyed by koanf paths, with every Secret redacted.
+func Dump(cfg *Config) map[string]any {
+	return plainValue(reflect.ValueOf(cfg)).(map[string]any)
+}
+
+// WriteDump prints Dump(cfg) as json, yaml or toml.
+func WriteDump(w io.Writer, cfg *Config, format string) error {
+	dump := Dump(cfg)
+
+	var (
+		out []byte
+		err error
+	)
+	switch format {
+	case "json":
+		out, err = json.MarshalIndent(dump, "", "  ")
+		out = append(out, '\n')
+	case "yaml":
+		out, err = yaml.Parser().Marshal(dump)
+	case "toml":
+		out, err = toml.Parser().Marshal(dump)
+	default:
+		return fmt.Errorf("unsupported dump format %q (must be one of json, yaml, toml)", format)
+	}
+	if err != nil {
+		return fmt.Errorf("marshalling config: %w", err)
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// JSONSchema builds a JSON Schema for Config from Describe. Each property
+// carries the environment variable that sets it as x-env-var.
+func JSONSchema(defaults *Config) map[string]any {
+	root := map[string]any{
+		"$schema":    jsonSchemaDraft,
+		"title":      "boilerplate configuration",
+		"type":       "object",
+		"properties": map[string]any{},
+	}
+	nodes := map[string]map[string]any{"": root}
+
+	for _, spec := range Describe(defaults) {
+		parentPath, name := "", spec.Path
+		if i := strings.LastIndexByte(spec.Path, '.'); i >= 0 {
+			parentPath, name = spec.Path[:i], spec.Path[i+1:]
+		}
+		parent := nodes[parentPath]
+
+		node := schemaType(spec.Type)
+		if spec.Type != "object" {
+			node["x-env-var"] = spec.EnvVar
+		}
+		if spec.Default != nil {
+			node["default"] = spec.Default
+		}
+		if spec.Secret {
+			node["writeOnly"] = true
+		}
+
+		parent["properties"].(map[string]any)[name] = node
+		if spec.Required {
+			required, _ := parent["required"].([]string)
+			parent["required"] = append(required, name)
+		}
+		nodes[spec.Path] = node
+	}
+
+	return root
+}
+
+func schemaType(name string) map[string]any {
+	switch {
+	case name == "object":
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	case name == "duration":
+		return map[string]any{"type": "string", "format": "duration"}
+	case strings.HasPrefix(name, "[]"):
+		return map[string]any{"type": "array", "items": schemaType(strings.TrimPrefix(name, "[]"))}
+	default:
+		return map[string]any{"type": name}
+	}
+}
+
+// WriteSchema prints the config reference either as a JSON Schema ("json")
+// or as a table of every supported environment variable ("env").
+func WriteSchema(w io.Writer, defaults *Config, format string) error {
+	switch format {
+	case "json":
+		out, err := json.MarshalIndent(JSONSchema(defaults), "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshalling config schema: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	case "env":
+		return writeEnvReference(w, Describe(defaults))
+	default:
+		return fmt.Errorf("unsupported schema format %q (must be one of json, env)", format)
+	}
+}
+
+func writeEnvReference(w io.Writer, specs []FieldSpec) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIABLE\tTYPE\tREQUIRED\tSECRET\tDEFAULT\tRULES")
+
+	for _, spec := range specs {
+		if spec.Type == "object" {
+			continue
+		}
+
+		def := ""
+		if list, ok := spec.Default.([]any); ok {
+			items := make([]string, 0, len(list))
+			for _, item := range list {
+				items = append(items, fmt.Sprint(item))
+			}
+			def = strings.Join(items, ",")
+		} else if spec.Default != nil {
+			def = fmt.Sprint(spec.Default)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%t\t%s\t%s\n",
+			spec.EnvVar, spec.Type, spec.Required, spec.Secret, def, spec.Rules)
+	}
+
+	return tw.Flush()
+}
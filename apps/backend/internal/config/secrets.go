@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+const (
+	// FileReferencePrefix marks a value read from a file, e.g. file:///run/secrets/db_password.
+	FileReferencePrefix = "file://"
+
+	// SecretReferencePrefix marks a value resolved through a SecretProvider, e.g. secret://db_password.
+	SecretReferencePrefix = "secret://"
+
+	// SecretProviderEnv selects the SecretProvider used for secret:// references ("file" or "env").
+	SecretProviderEnv = "BOILERPLATE_SECRET_PROVIDER"
+
+	// SecretsDirEnv overrides DefaultSecretsDir for the file provider.
+	SecretsDirEnv = "BOILERPLATE_SECRETS_DIR"
+
+	// DefaultSecretsDir is where Docker and Kubernetes mount secrets.
+	DefaultSecretsDir = "/run/secrets"
+
+	// SecretEnvPrefix is prepended to secret names by the env provider.
+	SecretEnvPrefix = "SECRET_"
+
+	// RedactedSecret replaces secret values whenever the config is printed.
+	RedactedSecret = "[REDACTED]"
+)
+
+// Secret is a configuration value that is redacted whenever it is printed,
+// logged or marshalled. Use Value to read the plain text.
+type Secret string
+
+// Value returns the plain-text secret.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return RedactedSecret
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// MarshalText keeps secrets out of JSON and YAML output.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// SecretProvider resolves the name in a secret://<name> reference.
+type SecretProvider interface {
+	GetSecret(name string) (string, error)
+}
+
+// FileSecretProvider reads each secret from a file named after it in Dir.
+type FileSecretProvider struct {
+	Dir string
+}
+
+func NewFileSecretProvider(dir string) *FileSecretProvider {
+	return &FileSecretProvider{Dir: dir}
+}
+
+func (p *FileSecretProvider) GetSecret(name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	return readSecretFile(filepath.Join(p.Dir, name))
+}
+
+// EnvSecretProvider reads each secret from the environment variable
+// Prefix + upper-cased name, so secret://db_password reads SECRET_DB_PASSWORD.
+type EnvSecretProvider struct {
+	Prefix string
+}
+
+func NewEnvSecretProvider(prefix string) *EnvSecretProvider {
+	return &EnvSecretProvider{Prefix: prefix}
+}
+
+func (p *EnvSecretProvider) GetSecret(name string) (string, error) {
+	key := p.Prefix + strings.ToUpper(name)
+
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", key)
+	}
+
+	return value, nil
+}
+
+// DefaultSecretProvider picks the provider named by SecretProviderEnv,
+// falling back to files in SecretsDirEnv or DefaultSecretsDir.
+func DefaultSecretProvider() SecretProvider {
+	if os.Getenv(SecretProviderEnv) == "env" {
+		return NewEnvSecretProvider(SecretEnvPrefix)
+	}
+
+	dir := os.Getenv(SecretsDirEnv)
+	if dir == "" {
+		dir = DefaultSecretsDir
+	}
+
+	return NewFileSecretProvider(dir)
+}
+
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+
+	// secret files are usually written with a trailing newline
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveReferences replaces every file:// and secret:// value in k with
+// the value it points to.
+func resolveReferences(k *koanf.Koanf, provider SecretProvider) ValidationErrors {
+	var problems ValidationErrors
+
+	for _, key := range k.Keys() {
+		value, ok := k.Get(key).(string)
+		if !ok {
+			continue
+		}
+
+		var (
+			resolved string
+			err      error
+		)
+		switch {
+		case strings.HasPrefix(value, FileReferencePrefix):
+			resolved, err = readSecretFile(strings.TrimPrefix(value, FileReferencePrefix))
+		case strings.HasPrefix(value, SecretReferencePrefix):
+			if provider == nil {
+				err = fmt.Errorf("no secret provider configured")
+				break
+			}
+			resolved, err = provider.GetSecret(strings.TrimPrefix(value, SecretReferencePrefix))
+		default:
+			continue
+		}
+
+		if err != nil {
+			problems = append(problems, newFieldError(key, fmt.Sprintf("could not resolve %s: %v", value, err)))
+			continue
+		}
+
+		if err := k.Set(key, resolved); err != nil {
+			problems = append(problems, newFieldError(key, err.Error()))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/knadh/koanf/providers/file"
+	"github.com/rs/zerolog"
+)
+
+// Subscriber is notified after a reloaded configuration has become active.
+type Subscriber func(previous, current *Config)
+
+// Watcher holds the active configuration and swaps it when a reload passes
+// validation. Values that are not picked up by a subscriber only take effect
+// after a restart.
+type Watcher struct {
+	opts    LoadOptions
+	logger  *zerolog.Logger
+	current atomic.Pointer[Config]
+	sources atomic.Pointer[Sources]
+
+	// mu serialises reloads and guards subscribers
+	mu          sync.Mutex
+	subscribers []Subscriber
+}
+
+// NewWatcher creates a watcher seeded with the configuration loaded at startup.
+func NewWatcher(opts LoadOptions, cfg *Config, sources *Sources, logger *zerolog.Logger) *Watcher {
+	w := &Watcher{
+		opts:   opts,
+		logger: logger,
+	}
+	w.current.Store(cfg)
+	w.sources.Store(sources)
+
+	return w
+}
+
+// Config returns the active configuration.
+func (w *Watcher) Config() *Config {
+	return w.current.Load()
+}
+
+// Subscribe registers fn to be called after every successful reload.
+func (w *Watcher) Subscribe(fn Subscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload loads and validates the configuration again. An invalid
+// configuration is rejected and the previous one stays active.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cfg, sources, err := Load(w.opts)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("config reload rejected, keeping previous config")
+		return err
+	}
+
+	previous := w.current.Swap(cfg)
+	w.sources.Store(sources)
+
+	w.logger.Info().Strs("layers", sources.Layers).Msg("config reloaded")
+
+	for _, fn := range w.subscribers {
+		fn(previous, cfg)
+	}
+
+	return nil
+}
+
+// Start reloads the configuration on SIGHUP and whenever one of the config
+// files loaded at startup changes, until ctx is done.
+func (w *Watcher) Start(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	var watched []*file.File
+	for _, path := range w.sources.Load().Files() {
+		f := file.Provider(path)
+		err := f.Watch(func(_ any, err error) {
+			if err != nil {
+				w.logger.Error().Err(err).Str("file", path).Msg("config file watch failed")
+				return
+			}
+
+			w.logger.Info().Str("file", path).Msg("config file changed, reloading")
+			_ = w.Reload()
+		})
+		if err != nil {
+			w.logger.Warn().Err(err).Str("file", path).Msg("could not watch config file")
+			continue
+		}
+		watched = append(watched, f)
+	}
+
+	go func() {
+		defer func() {
+			signal.Stop(hangup)
+			for _, f := range watched {
+				_ = f.Unwatch()
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				w.logger.Info().Msg("received SIGHUP, reloading config")
+				_ = w.Reload()
+			}
+		}
+	}()
+}
@@ -6,14 +6,15 @@ import (
 	"net"
 	"net/url"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/C0deNe0/go-boiler/internal/config"
 	loggerConfig "github.com/C0deNe0/go-boiler/internal/logger"
 	pgxzero "github.com/jackc/pgx-zerolog"
 	"github.com/newrelic/go-agent/v3/integrations/nrpgx5"
+	"github.com/newrelic/go-agent/v3/newrelic"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/tracelog"
 	"github.com/rs/zerolog"
@@ -22,86 +23,80 @@ import (
 type Database struct {
 	Pool *pgxpool.Pool
 	log  *zerolog.Logger
-}
-
-type multiTracer struct {
-	traces []any
-}
 
-const DatabasePingTimeout = 10
-
-//TraceQueryStart implements pgx tracer interface
-
-func (mt *multiTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
-	for _, tracer := range mt.traces {
-		if t, ok := tracer.(interface {
-			TracerQueryStart(context.Context, *pgx.Conn, pgx.TraceQueryStartData) context.Context
-		}); ok {
-			ctx = t.TracerQueryStart(ctx, conn, data)
-		}
-	}
-	return ctx
+	replicas      []*Replica
+	nextReplica   atomic.Uint64
+	replicaMaxLag time.Duration
+	// replicaCheckInterval is picked up by the replica monitor when
+	// replicaCheckReset fires
+	replicaCheckInterval atomic.Int64
+	replicaCheckReset    chan struct{}
 
+	// stop ends the replica monitor and the stats collector
+	stop context.CancelFunc
 }
 
-// TraceQueryEnd implements pgx tracer interface
+const DatabasePingTimeout = 10
 
-func (mt *multiTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
-	for _, tracer := range mt.traces {
-		if t, ok := tracer.(interface {
-			TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData)
-		}); ok {
-			t.TraceQueryEnd(ctx, conn, data)
-		}
+// DSN builds the connection string shared by the pool and the migrator.
+// User and password are escaped by url.URL.
+func DSN(cfg config.DatabaseConfig) string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password.Value()),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     "/" + cfg.Name,
+		RawQuery: url.Values{"sslmode": []string{cfg.SSLMode}}.Encode(),
 	}
-
+	return dsn.String()
 }
 
-func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerConfig.LoggerService) (*Database, error) {
-	hostPort := net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port))
-
-	//url encoded password
-	encodedPassword := url.QueryEscape(cfg.Database.Password)
-	dns := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s",
-		cfg.Database.User,
-		encodedPassword,
-		hostPort,
-		cfg.Database.Name,
-		cfg.Database.SSLMode, //
-
-	)
-	pgxPoolConfig, err := pgxpool.ParseConfig(dns)
+// New connects to the primary and any replicas. Tracers are added to the
+// pgx tracer chain after the built-in New Relic, local query log and slow
+// query tracers; each may implement any of the pgx tracer interfaces.
+func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerConfig.LoggerService, tracers ...any) (*Database, error) {
+	pgxPoolConfig, err := pgxpool.ParseConfig(DSN(cfg.Database))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse pgx pool config : %w", err)
 	}
+	applyPoolSettings(pgxPoolConfig, cfg)
+
+	var traces []any
 
 	//Add if has newrelic postgres instrumental
 	if loggerService != nil && loggerService.GetApplication() != nil {
-		pgxPoolConfig.ConnConfig.Tracer = nrpgx5.NewTracer()
+		traces = append(traces, nrpgx5.NewTracer())
 	}
 
 	if cfg.Primary.Env == "local" {
 		globalLever := logger.GetLevel()
+		if loggerService != nil {
+			// loggers created by the service filter levels themselves
+			globalLever = loggerService.Level()
+		}
 		pgxLogger := loggerConfig.NewPgxLogger(globalLever)
 
-		if pgxPoolConfig.ConnConfig.Tracer != nil {
-			//if new relic tracer exist, create a multi-tracer
-
-			localTracer := &tracelog.TraceLog{
-				Logger:   pgxzero.NewLogger(pgxLogger),
-				LogLevel: tracelog.LogLevel(loggerConfig.GetPgxTraceLogLevel(globalLever)),
-			}
-			pgxPoolConfig.ConnConfig.Tracer = &multiTracer{
-				traces: []any{pgxPoolConfig.ConnConfig.Tracer, localTracer},
-			}
-		} else {
-			pgxPoolConfig.ConnConfig.Tracer = &tracelog.TraceLog{
-				Logger:   pgxzero.NewLogger(pgxLogger),
-				LogLevel: tracelog.LogLevel(loggerConfig.GetPgxTraceLogLevel(globalLever)),
-			}
-		}
+		traces = append(traces, &tracelog.TraceLog{
+			Logger:   pgxzero.NewLogger(pgxLogger),
+			LogLevel: tracelog.LogLevel(loggerConfig.GetPgxTraceLogLevel(globalLever)),
+		})
+	}
+
+	// slow queries are logged in every environment
+	slowQueries := NewSlowQueryTracer(logger, 0, 0)
+	if cfg.Observeability != nil {
+		slowQueries = NewSlowQueryTracer(logger,
+			cfg.Observeability.Logging.SlowQueryTreshold,
+			cfg.Observeability.Logging.SlowQueryExplainRate)
+	}
+	traces = append(traces, slowQueries)
+	traces = append(traces, tracers...)
 
+	tracerChain, err := NewTracerChain(traces...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pgx tracer chain: %w", err)
 	}
+	pgxPoolConfig.ConnConfig.Tracer = tracerChain
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), pgxPoolConfig)
 	if err != nil {
@@ -112,6 +107,7 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerConfig
 		Pool: pool,
 		log:  logger,
 	}
+	slowQueries.Explainer = pool
 
 	ctx, cancel := context.WithTimeout(context.Background(), DatabasePingTimeout*time.Second)
 	defer cancel()
@@ -119,12 +115,29 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerConfig
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	background, stop := context.WithCancel(context.Background())
+	database.stop = stop
+
+	if err := database.connectReplicas(background, pgxPoolConfig, cfg.Database); err != nil {
+		stop()
+		pool.Close()
+		return nil, err
+	}
+
+	var app *newrelic.Application
+	if loggerService != nil {
+		app = loggerService.GetApplication()
+	}
+	database.startStatsCollector(background, time.Duration(cfg.Database.PoolStatsInterval)*time.Second, app)
+
 	logger.Info().Msg("Connected to database ")
 	return database, nil
 }
 
 func (db *Database) Close() error {
 	db.log.Info().Msg("closing database connection")
+	db.stop()
+	db.closeReplicas()
 	db.Pool.Close()
 	return nil
 }
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLeaderCheckInterval is how often a LeaderElection tries to take the
+// lock, or checks that it still holds it.
+const DefaultLeaderCheckInterval = 5 * time.Second
+
+// LeaderElection keeps at most one instance leader for a name by holding a
+// session advisory lock on a dedicated connection. Leadership is lost when
+// that connection breaks, and then sought again.
+type LeaderElection struct {
+	db       *Database
+	name     string
+	key      int64
+	interval time.Duration
+
+	leader  atomic.Bool
+	changes chan bool
+	lock    *AdvisoryLock
+
+	stop context.CancelFunc
+	done sync.WaitGroup
+}
+
+// NewLeaderElection prepares an election for name. A zero interval means
+// DefaultLeaderCheckInterval. Call Start to take part.
+func (db *Database) NewLeaderElection(name string, interval time.Duration) *LeaderElection {
+	if interval <= 0 {
+		interval = DefaultLeaderCheckInterval
+	}
+
+	return &LeaderElection{
+		db:       db,
+		name:     name,
+		key:      LockKey("leader:" + name),
+		interval: interval,
+		changes:  make(chan bool, 1),
+	}
+}
+
+// IsLeader reports whether this instance currently holds leadership.
+func (e *LeaderElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Changes receives true when leadership is gained and false when it is lost.
+// Only the latest change is buffered, so slow readers see the current state.
+func (e *LeaderElection) Changes() <-chan bool {
+	return e.changes
+}
+
+// Start campaigns in the background until Stop is called.
+func (e *LeaderElection) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.stop = cancel
+
+	e.done.Add(1)
+	go func() {
+		defer e.done.Done()
+
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			e.check(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the campaign and releases leadership.
+func (e *LeaderElection) Stop() {
+	if e.stop == nil {
+		return
+	}
+
+	e.stop()
+	e.done.Wait()
+
+	if e.lock != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), DatabasePingTimeout*time.Second)
+		defer cancel()
+
+		if err := e.lock.Release(ctx); err != nil {
+			e.db.log.Error().Err(err).Str("election", e.name).Msg("failed to release leadership")
+		}
+		e.lock = nil
+		e.setLeader(false)
+	}
+}
+
+func (e *LeaderElection) check(ctx context.Context) {
+	if e.lock != nil {
+		if _, err := e.lock.conn.Exec(ctx, "select 1"); err == nil || ctx.Err() != nil {
+			return
+		}
+
+		// the session, and with it the lock, is gone
+		e.db.log.Warn().Str("election", e.name).Msg("lost leadership")
+		_ = e.lock.conn.Conn().Close(ctx)
+		e.lock.conn.Release()
+		e.lock = nil
+		e.setLeader(false)
+		return
+	}
+
+	lock, err := e.db.TryLock(ctx, e.key, SessionLock)
+	if err != nil {
+		if !errors.Is(err, ErrLockNotAcquired) && ctx.Err() == nil {
+			e.db.log.Error().Err(err).Str("election", e.name).Msg("leader election failed")
+		}
+		return
+	}
+
+	e.lock = lock
+	e.db.log.Info().Str("election", e.name).Msg("gained leadership")
+	e.setLeader(true)
+}
+
+func (e *LeaderElection) setLeader(leader bool) {
+	e.leader.Store(leader)
+
+	// replace an unread change with the current state
+	select {
+	case <-e.changes:
+	default:
+	}
+	e.changes <- leader
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockRetryInterval is how often Lock retries while waiting for a held lock.
+const lockRetryInterval = 100 * time.Millisecond
+
+// ErrLockNotAcquired is returned when an advisory lock is held elsewhere.
+var ErrLockNotAcquired = errors.New("advisory lock not acquired")
+
+// LockScope decides how long an advisory lock is held.
+type LockScope int
+
+const (
+	// SessionLock is held on a dedicated connection until Release.
+	SessionLock LockScope = iota
+	// TransactionLock is held until the transaction carried by the context
+	// ends; Release is a no-op.
+	TransactionLock
+)
+
+// LockKey derives an advisory lock key from a name, so callers need not
+// coordinate numeric keys.
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64()) //nolint:gosec // the key only needs to be stable
+}
+
+// lockQuerier is satisfied by pgx.Conn, pgxpool.Conn and pgx.Tx.
+type lockQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func tryAdvisoryLock(ctx context.Context, q lockQuerier, key int64, scope LockScope) (bool, error) {
+	sql := "select pg_try_advisory_lock($1)"
+	if scope == TransactionLock {
+		sql = "select pg_try_advisory_xact_lock($1)"
+	}
+
+	var acquired bool
+	if err := q.QueryRow(ctx, sql, key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("acquiring advisory lock %d: %w", key, err)
+	}
+	return acquired, nil
+}
+
+// advisoryLock waits for a session lock without a timeout.
+func advisoryLock(ctx context.Context, q lockQuerier, key int64) error {
+	if err := q.QueryRow(ctx, "select pg_advisory_lock($1)", key).Scan(nil); err != nil {
+		return fmt.Errorf("acquiring advisory lock %d: %w", key, err)
+	}
+	return nil
+}
+
+func advisoryUnlock(ctx context.Context, q lockQuerier, key int64) error {
+	var released bool
+	if err := q.QueryRow(ctx, "select pg_advisory_unlock($1)", key).Scan(&released); err != nil {
+		return fmt.Errorf("releasing advisory lock %d: %w", key, err)
+	}
+	if !released {
+		return fmt.Errorf("releasing advisory lock %d: lock was not held", key)
+	}
+	return nil
+}
+
+// AdvisoryLock is an advisory lock acquired by TryLock or Lock.
+type AdvisoryLock struct {
+	key  int64
+	conn *pgxpool.Conn
+}
+
+// Key returns the advisory lock key.
+func (l *AdvisoryLock) Key() int64 {
+	return l.key
+}
+
+// Release unlocks a session lock and returns its connection to the pool.
+// Transaction locks are released by the end of their transaction.
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+
+	conn := l.conn
+	l.conn = nil
+
+	err := advisoryUnlock(ctx, conn, l.key)
+	if err != nil {
+		// closing the session is the only other way to drop the lock
+		_ = conn.Conn().Close(ctx)
+	}
+	conn.Release()
+	return err
+}
+
+// TryLock acquires the advisory lock key without waiting and returns
+// ErrLockNotAcquired when it is held elsewhere. TransactionLock requires ctx
+// to carry a transaction started by WithTx.
+func (db *Database) TryLock(ctx context.Context, key int64, scope LockScope) (*AdvisoryLock, error) {
+	if scope == TransactionLock {
+		tx, ok := TxFromContext(ctx)
+		if !ok {
+			return nil, errors.New("transaction lock requires a transaction in the context")
+		}
+
+		acquired, err := tryAdvisoryLock(ctx, tx, key, scope)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			return nil, ErrLockNotAcquired
+		}
+		return &AdvisoryLock{key: key}, nil
+	}
+
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquiring connection for advisory lock: %w", err)
+	}
+
+	acquired, err := tryAdvisoryLock(ctx, conn, key, scope)
+	if err != nil || !acquired {
+		conn.Release()
+		if err == nil {
+			err = ErrLockNotAcquired
+		}
+		return nil, err
+	}
+	return &AdvisoryLock{key: key, conn: conn}, nil
+}
+
+// Lock waits up to timeout for the advisory lock key, returning
+// ErrLockNotAcquired when it is still held elsewhere. A zero timeout waits
+// until ctx is done.
+func (db *Database) Lock(ctx context.Context, key int64, scope LockScope, timeout time.Duration) (*AdvisoryLock, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		lock, err := db.TryLock(ctx, key, scope)
+		if !errors.Is(err, ErrLockNotAcquired) {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ErrLockNotAcquired, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
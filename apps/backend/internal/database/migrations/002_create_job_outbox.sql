@@ -0,0 +1,23 @@
+-- Jobs written in the same transaction as the data they belong to and
+-- published to asynq by the outbox relay.
+CREATE TABLE job_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    task_type TEXT NOT NULL,
+    payload BYTEA NOT NULL,
+    queue TEXT NOT NULL,
+    max_retry INTEGER NOT NULL,
+    timeout_seconds INTEGER NOT NULL,
+    dedup_key TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX job_outbox_dedup_key_idx ON job_outbox (dedup_key) WHERE dedup_key IS NOT NULL;
+CREATE INDEX job_outbox_pending_idx ON job_outbox (available_at, id) WHERE published_at IS NULL;
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS job_outbox;
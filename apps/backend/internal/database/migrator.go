@@ -3,66 +3,408 @@ package database
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
-	"net"
-	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/C0deNe0/go-boiler/internal/config"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	tern "github.com/jackc/tern/v2/migrate"
 	"github.com/rs/zerolog"
 )
 
-// go:Embed migrations/*.sql
+//go:embed migrations/*.sql
 var migrations embed.FS
 
-func Migrate(ctx context.Context, looger *zerolog.Logger, cfg *config.Config) error {
-	hostPort := net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port))
+const (
+	// migrationVersionTable records the applied schema version
+	migrationVersionTable = "schema_version"
+
+	// migrationLockID is the advisory lock key held while migrating so that
+	// replicas starting together apply migrations one at a time
+	migrationLockID int64 = 7_382_640_116
+
+	pgUndefinedTable = "42P01"
+
+	// migrationSeparator splits the up and down sections of a tern migration
+	migrationSeparator = "---- create above / drop below ----"
+)
+
+// ErrSchemaAhead is returned when the database has migrations this binary does not embed.
+var ErrSchemaAhead = errors.New("database schema is newer than the embedded migrations")
+
+// ErrSchemaBehind is returned by CheckSchemaVersion while migrations are pending.
+var ErrSchemaBehind = errors.New("database schema is older than the embedded migrations")
+
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_.+\.sql$`)
 
-	encodedPassword := url.QueryEscape(cfg.Database.Password)
-	dsn := fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=%s",
-		cfg.Database.Host,
-		encodedPassword,
-		hostPort,
-		cfg.Database.Name,
-		cfg.Database.SSLMode,
-	)
+// MigrationInfo describes one embedded migration.
+type MigrationInfo struct {
+	Sequence int32  `json:"sequence"`
+	Name     string `json:"name"`
+	Applied  bool   `json:"applied"`
+}
+
+// MigrationStatus compares the database schema with the embedded migrations.
+type MigrationStatus struct {
+	Current    int32           `json:"current"`
+	Latest     int32           `json:"latest"`
+	Migrations []MigrationInfo `json:"migrations"`
+}
+
+// Pending returns the migrations that have not been applied yet.
+func (s *MigrationStatus) Pending() []MigrationInfo {
+	var pending []MigrationInfo
+	for _, migration := range s.Migrations {
+		if !migration.Applied {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+// MigrationStep is a single migration that MigrateTo would run.
+type MigrationStep struct {
+	Sequence  int32
+	Name      string
+	Direction string
+	SQL       string
+}
 
-	conn, err := pgx.Connect(ctx, dsn)
+// Migrator applies the embedded migrations to the configured database.
+type Migrator struct {
+	conn   *pgx.Conn
+	tern   *tern.Migrator
+	logger *zerolog.Logger
+}
+
+// NewMigrator connects to the database and loads the embedded migrations.
+// The caller must Close it.
+func NewMigrator(ctx context.Context, looger *zerolog.Logger, cfg *config.Config) (*Migrator, error) {
+	conn, err := pgx.Connect(ctx, DSN(cfg.Database))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer conn.Close(ctx)
-	m, err := tern.NewMigrator(ctx, conn, "schema_version")
+	m, err := tern.NewMigrator(ctx, conn, migrationVersionTable)
 	if err != nil {
-		return fmt.Errorf("constructing database migrator:%w", err)
+		conn.Close(ctx)
+		return nil, fmt.Errorf("constructing database migrator:%w", err)
 	}
 
-	subtree, err := fs.Sub(migrations, "migrations")
+	subtree, err := EmbeddedMigrations()
 	if err != nil {
-		return fmt.Errorf("retreving database migration subtree:%w", err)
+		conn.Close(ctx)
+		return nil, err
 	}
 
 	if err := m.LoadMigrations(subtree); err != nil {
-		return fmt.Errorf("Loading database migrations: %w", err)
+		conn.Close(ctx)
+		return nil, fmt.Errorf("Loading database migrations: %w", err)
+	}
+
+	return &Migrator{
+		conn:   conn,
+		tern:   m,
+		logger: looger,
+	}, nil
+}
+
+func (m *Migrator) Close(ctx context.Context) error {
+	return m.conn.Close(ctx)
+}
+
+// CurrentVersion returns the schema version recorded in the database.
+func (m *Migrator) CurrentVersion(ctx context.Context) (int32, error) {
+	version, err := m.tern.GetCurrentVersion(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("retriving current database migration version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestVersion returns the version of the newest embedded migration.
+func (m *Migrator) LatestVersion() int32 {
+	return int32(len(m.tern.Migrations))
+}
+
+// Status reports the current version and which embedded migrations are applied.
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	current, err := m.CurrentVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{
+		Current: current,
+		Latest:  m.LatestVersion(),
+	}
+	for _, migration := range m.tern.Migrations {
+		status.Migrations = append(status.Migrations, MigrationInfo{
+			Sequence: migration.Sequence,
+			Name:     migration.Name,
+			Applied:  migration.Sequence <= current,
+		})
+	}
+
+	return status, nil
+}
+
+// Plan lists the steps that migrating to target would run, without running them.
+func (m *Migrator) Plan(ctx context.Context, target int32) ([]MigrationStep, error) {
+	current, err := m.CurrentVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkTarget(current, target); err != nil {
+		return nil, err
+	}
+
+	var steps []MigrationStep
+	for seq := current + 1; seq <= target; seq++ {
+		migration := m.tern.Migrations[seq-1]
+		steps = append(steps, MigrationStep{
+			Sequence:  migration.Sequence,
+			Name:      migration.Name,
+			Direction: "up",
+			SQL:       migration.UpSQL,
+		})
+	}
+	for seq := current; seq > target; seq-- {
+		migration := m.tern.Migrations[seq-1]
+		if migration.DownSQL == "" {
+			return nil, fmt.Errorf("migration %s has no down section and cannot be rolled back", migration.Name)
+		}
+		steps = append(steps, MigrationStep{
+			Sequence:  migration.Sequence,
+			Name:      migration.Name,
+			Direction: "down",
+			SQL:       migration.DownSQL,
+		})
+	}
+
+	return steps, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.LatestVersion())
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	from, err := m.CurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if from == 0 {
+		m.logger.Info().Msg("database schema has no migrations to roll back")
+		return nil
+	}
+
+	return m.To(ctx, from-1)
+}
+
+// DownTarget returns the version Down would migrate to.
+func (m *Migrator) DownTarget(ctx context.Context) (int32, error) {
+	from, err := m.CurrentVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return max(from-1, 0), nil
+}
+
+// To migrates up or down to the target version while holding the migration
+// lock, so concurrent callers wait and then find the schema up to date.
+func (m *Migrator) To(ctx context.Context, target int32) error {
+	if err := advisoryLock(ctx, m.conn, migrationLockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer func() {
+		if err := advisoryUnlock(ctx, m.conn, migrationLockID); err != nil {
+			m.logger.Error().Err(err).Msg("failed to release migration lock")
+		}
+	}()
+
+	// plan under the lock so the version cannot move underneath us
+	steps, err := m.Plan(ctx, target)
+	if err != nil {
+		return err
 	}
 
-	from, err := m.GetCurrentVersion(ctx)
+	from, err := m.CurrentVersion(ctx)
 	if err != nil {
-		return fmt.Errorf("retriving current database migration version")
+		return err
 	}
 
-	if err := m.Migrate(ctx); err != nil {
+	if err := m.tern.MigrateTo(ctx, target); err != nil {
 		return err
 	}
 
-	if from == int32(len(m.Migrations)) {
-		looger.Info().Msgf("database schema up to date, version %d", len(m.Migrations))
+	if len(steps) == 0 {
+		m.logger.Info().Msgf("database schema up to date, version %d", target)
 	} else {
-		looger.Info().Msgf("migrated database schema, from %d to %d", from, len(m.Migrations))
+		m.logger.Info().Msgf("migrated database schema, from %d to %d", from, target)
+	}
+	return nil
+}
+
+func (m *Migrator) checkTarget(current, target int32) error {
+	if current > m.LatestVersion() {
+		return fmt.Errorf("%w: database at version %d, latest embedded migration is %d", ErrSchemaAhead, current, m.LatestVersion())
+	}
+	if target < 0 || target > m.LatestVersion() {
+		return fmt.Errorf("target version %d out of range 0..%d", target, m.LatestVersion())
 	}
 	return nil
 }
+
+// EmbeddedMigrations returns the migration files compiled into the binary.
+func EmbeddedMigrations() (fs.FS, error) {
+	subtree, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("retreving database migration subtree:%w", err)
+	}
+	return subtree, nil
+}
+
+// LatestMigrationVersion counts the embedded migrations without connecting to the database.
+func LatestMigrationVersion() (int32, error) {
+	subtree, err := EmbeddedMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	paths, err := fs.Glob(subtree, "*.sql")
+	if err != nil {
+		return 0, fmt.Errorf("listing embedded migrations: %w", err)
+	}
+
+	var latest int32
+	for _, p := range paths {
+		if migrationFileRegex.MatchString(p) {
+			latest++
+		}
+	}
+	return latest, nil
+}
+
+// ValidateMigrations checks that the migrations in fsys are numbered 1..n
+// without gaps or duplicates and that each has an up and a down section.
+func ValidateMigrations(fsys fs.FS) error {
+	paths, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return fmt.Errorf("listing migrations: %w", err)
+	}
+
+	var problems []error
+	bySequence := make(map[int][]string)
+
+	for _, p := range paths {
+		matches := migrationFileRegex.FindStringSubmatch(p)
+		if matches == nil {
+			problems = append(problems, fmt.Errorf("%s: name must start with a sequence number, e.g. 001_create_users.sql", p))
+			continue
+		}
+
+		sequence, err := strconv.Atoi(matches[1])
+		if err != nil || sequence < 1 {
+			problems = append(problems, fmt.Errorf("%s: invalid sequence number %s", p, matches[1]))
+			continue
+		}
+		bySequence[sequence] = append(bySequence[sequence], p)
+
+		body, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", p, err))
+			continue
+		}
+
+		up, down, found := strings.Cut(string(body), migrationSeparator)
+		if !containsSQL(up) {
+			problems = append(problems, fmt.Errorf("%s: up section has no SQL", p))
+		}
+		if !found {
+			problems = append(problems, fmt.Errorf("%s: missing %q and down section", p, migrationSeparator))
+		} else if !containsSQL(down) {
+			problems = append(problems, fmt.Errorf("%s: down section has no SQL", p))
+		}
+	}
+
+	sequences := make([]int, 0, len(bySequence))
+	for sequence, files := range bySequence {
+		sequences = append(sequences, sequence)
+		if len(files) > 1 {
+			problems = append(problems, fmt.Errorf("duplicate migration number %d: %s", sequence, strings.Join(files, ", ")))
+		}
+	}
+	slices.Sort(sequences)
+	for i, sequence := range sequences {
+		if sequence != i+1 {
+			problems = append(problems, fmt.Errorf("migration numbers must be consecutive from 1: expected %d, found %d", i+1, sequence))
+			break
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// containsSQL reports whether sql has anything besides blank lines and comments.
+func containsSQL(sql string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSchemaVersion refuses to run against a schema that does not match
+// the migrations embedded in this binary: one that is newer, e.g. after a
+// rollback to an older release, or one with pending migrations, including a
+// database that was never migrated. Migrations are applied by the migrate
+// command, never on startup.
+func (db *Database) CheckSchemaVersion(ctx context.Context) error {
+	var current int32
+	err := db.Pool.QueryRow(ctx, "select version from "+migrationVersionTable).Scan(&current)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != pgUndefinedTable {
+			return fmt.Errorf("retriving current database migration version: %w", err)
+		}
+		// never migrated
+		current = 0
+	}
+
+	latest, err := LatestMigrationVersion()
+	if err != nil {
+		return err
+	}
+	if current > latest {
+		return fmt.Errorf("%w: database at version %d, latest embedded migration is %d", ErrSchemaAhead, current, latest)
+	}
+	if current < latest {
+		return fmt.Errorf("%w: database at version %d, migrations %d to %d are pending; run `go-boilerplate migrate up`",
+			ErrSchemaBehind, current, current+1, latest)
+	}
+
+	db.log.Info().Int32("version", current).Int32("latest", latest).Msg("database schema version checked")
+	return nil
+}
+
+// Migrate applies every pending migration.
+func Migrate(ctx context.Context, looger *zerolog.Logger, cfg *config.Config) error {
+	m, err := NewMigrator(ctx, looger, cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Close(ctx)
+
+	return m.Up(ctx)
+}
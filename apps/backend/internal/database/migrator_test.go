@@ -0,0 +1,100 @@
+package database_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/C0deNe0/go-boiler/internal/database"
+)
+
+const migrationBody = "CREATE TABLE t (id INT);\n\n---- create above / drop below ----\n\nDROP TABLE t;\n"
+
+func TestEmbeddedMigrationsAreValid(t *testing.T) {
+	migrations, err := database.EmbeddedMigrations()
+	if err != nil {
+		t.Fatalf("loading embedded migrations: %v", err)
+	}
+
+	if err := database.ValidateMigrations(migrations); err != nil {
+		t.Fatalf("embedded migrations are invalid:\n%v", err)
+	}
+}
+
+func TestValidateMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{
+			name: "valid",
+			files: fstest.MapFS{
+				"001_first.sql":  {Data: []byte(migrationBody)},
+				"002_second.sql": {Data: []byte(migrationBody)},
+			},
+		},
+		{
+			name: "gap in numbering",
+			files: fstest.MapFS{
+				"001_first.sql": {Data: []byte(migrationBody)},
+				"003_third.sql": {Data: []byte(migrationBody)},
+			},
+			wantErr: "expected 2, found 3",
+		},
+		{
+			name: "not starting at one",
+			files: fstest.MapFS{
+				"002_second.sql": {Data: []byte(migrationBody)},
+			},
+			wantErr: "expected 1, found 2",
+		},
+		{
+			name: "duplicate number",
+			files: fstest.MapFS{
+				"001_first.sql": {Data: []byte(migrationBody)},
+				"001_other.sql": {Data: []byte(migrationBody)},
+			},
+			wantErr: "duplicate migration number 1",
+		},
+		{
+			name: "unnumbered file",
+			files: fstest.MapFS{
+				"001_first.sql": {Data: []byte(migrationBody)},
+				"users.sql":     {Data: []byte(migrationBody)},
+			},
+			wantErr: "users.sql: name must start with a sequence number",
+		},
+		{
+			name: "missing down section",
+			files: fstest.MapFS{
+				"001_first.sql": {Data: []byte("CREATE TABLE t (id INT);\n")},
+			},
+			wantErr: "001_first.sql: missing",
+		},
+		{
+			name: "empty down section",
+			files: fstest.MapFS{
+				"001_first.sql": {Data: []byte("CREATE TABLE t (id INT);\n---- create above / drop below ----\n-- nothing to undo\n")},
+			},
+			wantErr: "001_first.sql: down section has no SQL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := database.ValidateMigrations(tt.files)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
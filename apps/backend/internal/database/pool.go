@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// DefaultPoolStatsInterval applies when DatabaseConfig.PoolStatsInterval is zero.
+const DefaultPoolStatsInterval = time.Minute
+
+// applyPoolSettings copies the pool settings from cfg onto poolConfig.
+// pgxpool has no cap on idle connections; connections above MinConns are
+// closed after ConnMaxIdleTime, so MaxIdleConns only bounds MinConns.
+func applyPoolSettings(poolConfig *pgxpool.Config, cfg *config.Config) {
+	db := cfg.Database
+
+	poolConfig.MaxConns = int32(db.MaxOpenConns)
+	poolConfig.MinConns = int32(min(db.MinConns, db.MaxIdleConns, db.MaxOpenConns))
+	poolConfig.MaxConnLifetime = time.Duration(db.ConnMaxLifeTime) * time.Second
+	poolConfig.MaxConnIdleTime = time.Duration(db.ConnMaxIdleTime) * time.Second
+	if db.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = time.Duration(db.HealthCheckPeriod) * time.Second
+	}
+
+	applicationName := db.ApplicationName
+	if applicationName == "" && cfg.Observeability != nil {
+		applicationName = cfg.Observeability.ServiceName
+	}
+	if applicationName != "" {
+		poolConfig.ConnConfig.RuntimeParams["application_name"] = applicationName
+	}
+
+	if db.StatementTimeout > 0 {
+		timeout := time.Duration(db.StatementTimeout) * time.Second
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(timeout.Milliseconds(), 10)
+	}
+}
+
+// startStatsCollector publishes the primary pool statistics to the log, at
+// Warn when acquires had to wait, and, when configured, to New Relic as
+// custom metrics until ctx is done.
+func (db *Database) startStatsCollector(ctx context.Context, interval time.Duration, app *newrelic.Application) {
+	if interval == 0 {
+		interval = DefaultPoolStatsInterval
+	}
+
+	var previous *pgxpool.Stat
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stat := db.Pool.Stat()
+				db.recordPoolStats(stat, previous, app)
+				previous = stat
+			}
+		}
+	}()
+}
+
+func (db *Database) recordPoolStats(stat, previous *pgxpool.Stat, app *newrelic.Application) {
+	// counters are cumulative, publish what happened during the interval
+	waitCount := stat.EmptyAcquireCount()
+	waitDuration := stat.EmptyAcquireWaitTime()
+	if previous != nil {
+		waitCount -= previous.EmptyAcquireCount()
+		waitDuration -= previous.EmptyAcquireWaitTime()
+	}
+
+	// requests that waited for a connection mean the pool is saturated
+	event := db.log.Info()
+	if waitCount > 0 {
+		event = db.log.Warn()
+	}
+	event.
+		Int32("total_conns", stat.TotalConns()).
+		Int32("acquired_conns", stat.AcquiredConns()).
+		Int32("idle_conns", stat.IdleConns()).
+		Int32("max_conns", stat.MaxConns()).
+		Int64("wait_count", waitCount).
+		Dur("wait_duration", waitDuration).
+		Msg("database pool stats")
+
+	if app == nil {
+		return
+	}
+
+	metrics := map[string]float64{
+		"TotalConns":     float64(stat.TotalConns()),
+		"AcquiredConns":  float64(stat.AcquiredConns()),
+		"IdleConns":      float64(stat.IdleConns()),
+		"MaxConns":       float64(stat.MaxConns()),
+		"WaitCount":      float64(waitCount),
+		"WaitDurationMs": float64(waitDuration.Milliseconds()),
+	}
+	for name, value := range metrics {
+		app.RecordCustomMetric("Custom/Database/Pool/"+name, value)
+	}
+}
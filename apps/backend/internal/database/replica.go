@@ -0,0 +1,273 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// DefaultReplicaMaxLag applies when DatabaseConfig.ReplicaMaxLag is zero.
+	DefaultReplicaMaxLag = 5 * time.Second
+
+	// DefaultReplicaHealthCheckInterval applies when DatabaseConfig.ReplicaHealthCheckInterval is zero.
+	DefaultReplicaHealthCheckInterval = 5 * time.Second
+
+	replicaCheckTimeout = 2 * time.Second
+
+	// replicaLagQuery reports zero once the replica has replayed everything it
+	// received, since pg_last_xact_replay_timestamp only moves on new writes.
+	replicaLagQuery = `
+SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END::float8`
+)
+
+// Replica is a read replica with its own pool and the outcome of the last health check.
+type Replica struct {
+	Name string
+	Pool *pgxpool.Pool
+
+	healthy atomic.Bool
+	lag     atomic.Int64
+	lastErr atomic.Pointer[string]
+}
+
+// ReplicaStatus is a snapshot of a replica's health.
+type ReplicaStatus struct {
+	Name    string        `json:"name"`
+	Healthy bool          `json:"healthy"`
+	Lag     time.Duration `json:"lag"`
+	Error   string        `json:"error,omitempty"`
+}
+
+func (r *Replica) status() ReplicaStatus {
+	status := ReplicaStatus{
+		Name:    r.Name,
+		Healthy: r.healthy.Load(),
+		Lag:     time.Duration(r.lag.Load()),
+	}
+	if msg := r.lastErr.Load(); msg != nil {
+		status.Error = *msg
+	}
+	return status
+}
+
+type sessionContextKey struct{}
+
+// session remembers when a request last wrote to the primary.
+type session struct {
+	lastWrite atomic.Int64
+}
+
+// WithSession enables read-your-writes for ctx: after a statement through
+// Querier that may write, a committed read-write WithTx or MarkWritten, Reader keeps
+// using the primary for ReplicaMaxLag so the request does not read stale
+// data from a replica.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, &session{})
+}
+
+// MarkWritten pins the session of ctx to the primary as if it had just
+// written. Querier does this for statements that may write; call it after
+// statements it cannot tell apart from reads, such as a SELECT of a function
+// that modifies data.
+func MarkWritten(ctx context.Context) {
+	if s, ok := ctx.Value(sessionContextKey{}).(*session); ok {
+		s.lastWrite.Store(time.Now().UnixNano())
+	}
+}
+
+func wroteWithin(ctx context.Context, window time.Duration) bool {
+	s, ok := ctx.Value(sessionContextKey{}).(*session)
+	if !ok {
+		return false
+	}
+	last := s.lastWrite.Load()
+	return last != 0 && time.Since(time.Unix(0, last)) < window
+}
+
+// Reader returns a Querier for read-only work: the transaction carried by
+// ctx, else a healthy replica, else the primary.
+func (db *Database) Reader(ctx context.Context) Querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db.readPool(ctx)
+}
+
+// readPool picks a healthy replica round-robin, falling back to the primary
+// when none is healthy or ctx wrote recently.
+func (db *Database) readPool(ctx context.Context) *pgxpool.Pool {
+	if len(db.replicas) == 0 || wroteWithin(ctx, db.replicaMaxLag) {
+		return db.Pool
+	}
+
+	start := db.nextReplica.Add(1)
+	for i := range len(db.replicas) {
+		replica := db.replicas[(int(start)+i)%len(db.replicas)]
+		if replica.healthy.Load() {
+			return replica.Pool
+		}
+	}
+
+	return db.Pool
+}
+
+// Replicas reports the health of every configured replica.
+func (db *Database) Replicas() []ReplicaStatus {
+	statuses := make([]ReplicaStatus, 0, len(db.replicas))
+	for _, replica := range db.replicas {
+		statuses = append(statuses, replica.status())
+	}
+	return statuses
+}
+
+// connectReplicas creates a pool per replica with the primary's pool settings
+// and starts monitoring them. Replicas are only used once a health check passes.
+func (db *Database) connectReplicas(ctx context.Context, primary *pgxpool.Config, cfg config.DatabaseConfig) error {
+	db.replicaMaxLag = time.Duration(cfg.ReplicaMaxLag) * time.Second
+	if db.replicaMaxLag == 0 {
+		db.replicaMaxLag = DefaultReplicaMaxLag
+	}
+
+	for _, rc := range cfg.Replicas {
+		port := rc.Port
+		if port == 0 {
+			port = cfg.Port
+		}
+
+		poolConfig, err := replicaPoolConfig(primary, cfg, rc.Host, port)
+		if err != nil {
+			db.closeReplicas()
+			return fmt.Errorf("failed to parse pgx pool config for replica %s: %w", rc.Host, err)
+		}
+
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			db.closeReplicas()
+			return fmt.Errorf("failed to create pgx pool for replica %s: %w", rc.Host, err)
+		}
+
+		db.replicas = append(db.replicas, &Replica{
+			Name: net.JoinHostPort(rc.Host, strconv.Itoa(port)),
+			Pool: pool,
+		})
+	}
+
+	if len(db.replicas) == 0 {
+		return nil
+	}
+
+	db.replicaCheckInterval.Store(int64(replicaHealthCheckInterval(cfg.ReplicaHealthCheckInterval)))
+	db.replicaCheckReset = make(chan struct{}, 1)
+
+	db.checkReplicas(ctx)
+	go func() {
+		ticker := time.NewTicker(time.Duration(db.replicaCheckInterval.Load()))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-db.replicaCheckReset:
+				ticker.Reset(time.Duration(db.replicaCheckInterval.Load()))
+			case <-ticker.C:
+				db.checkReplicas(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// replicaPoolConfig parses a config from the replica's own host and port, so
+// that TLS verifies the replica's name, and copies the primary's pool
+// settings, runtime parameters and tracer onto it.
+func replicaPoolConfig(primary *pgxpool.Config, cfg config.DatabaseConfig, host string, port int) (*pgxpool.Config, error) {
+	cfg.Host, cfg.Port = host, port
+	poolConfig, err := pgxpool.ParseConfig(DSN(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.MaxConns = primary.MaxConns
+	poolConfig.MinConns = primary.MinConns
+	poolConfig.MaxConnLifetime = primary.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = primary.MaxConnIdleTime
+	poolConfig.HealthCheckPeriod = primary.HealthCheckPeriod
+	maps.Copy(poolConfig.ConnConfig.RuntimeParams, primary.ConnConfig.RuntimeParams)
+	poolConfig.ConnConfig.Tracer = primary.ConnConfig.Tracer
+
+	return poolConfig, nil
+}
+
+// SetReplicaHealthCheckInterval changes how often replicas are probed, in
+// seconds, with zero meaning the default. It does nothing without replicas.
+func (db *Database) SetReplicaHealthCheckInterval(seconds int) {
+	if db.replicaCheckReset == nil {
+		return
+	}
+
+	db.replicaCheckInterval.Store(int64(replicaHealthCheckInterval(seconds)))
+	select {
+	case db.replicaCheckReset <- struct{}{}:
+	default:
+		// a reset is already pending and will read the new interval
+	}
+}
+
+func replicaHealthCheckInterval(seconds int) time.Duration {
+	if seconds == 0 {
+		return DefaultReplicaHealthCheckInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (db *Database) checkReplicas(ctx context.Context) {
+	for _, replica := range db.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+
+		var lagSeconds float64
+		err := replica.Pool.QueryRow(checkCtx, replicaLagQuery).Scan(&lagSeconds)
+		cancel()
+
+		lag := time.Duration(lagSeconds * float64(time.Second))
+		replica.lag.Store(int64(lag))
+
+		healthy := err == nil && lag <= db.replicaMaxLag
+		if err != nil {
+			msg := err.Error()
+			replica.lastErr.Store(&msg)
+		} else {
+			replica.lastErr.Store(nil)
+		}
+
+		if was := replica.healthy.Swap(healthy); was != healthy {
+			event := db.log.Info()
+			if !healthy {
+				event = db.log.Warn().Err(err)
+			}
+			event.Str("replica", replica.Name).
+				Dur("lag", lag).
+				Bool("healthy", healthy).
+				Msg("database replica health changed")
+		}
+	}
+}
+
+func (db *Database) closeReplicas() {
+	for _, replica := range db.replicas {
+		replica.Pool.Close()
+	}
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestSetReplicaHealthCheckInterval(t *testing.T) {
+	db := &Database{replicaCheckReset: make(chan struct{}, 1)}
+
+	db.SetReplicaHealthCheckInterval(30)
+	db.SetReplicaHealthCheckInterval(0)
+
+	if got := time.Duration(db.replicaCheckInterval.Load()); got != DefaultReplicaHealthCheckInterval {
+		t.Errorf("interval = %v, want %v", got, DefaultReplicaHealthCheckInterval)
+	}
+	if len(db.replicaCheckReset) != 1 {
+		t.Errorf("pending resets = %d, want 1", len(db.replicaCheckReset))
+	}
+
+	// without replicas there is no monitor to reset
+	(&Database{}).SetReplicaHealthCheckInterval(10)
+}
+
+func TestReplicaPoolConfig(t *testing.T) {
+	cfg := config.DatabaseConfig{
+		Host:    "primary.db.internal",
+		Port:    5432,
+		User:    "app",
+		Name:    "app",
+		SSLMode: "verify-full",
+	}
+	primary, err := pgxpool.ParseConfig(DSN(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary.MaxConns = 17
+	primary.ConnConfig.RuntimeParams["application_name"] = "boilerplate"
+	primary.ConnConfig.Tracer = &SlowQueryTracer{}
+
+	replica, err := replicaPoolConfig(primary, cfg, "replica-1.db.internal", 6432)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if replica.ConnConfig.Host != "replica-1.db.internal" || replica.ConnConfig.Port != 6432 {
+		t.Errorf("address = %s:%d, want replica-1.db.internal:6432", replica.ConnConfig.Host, replica.ConnConfig.Port)
+	}
+	if replica.ConnConfig.TLSConfig == nil || replica.ConnConfig.TLSConfig.ServerName != "replica-1.db.internal" {
+		t.Errorf("TLS server name = %v, want the replica host", replica.ConnConfig.TLSConfig)
+	}
+	if replica.MaxConns != 17 {
+		t.Errorf("MaxConns = %d, want 17", replica.MaxConns)
+	}
+	if got := replica.ConnConfig.RuntimeParams["application_name"]; got != "boilerplate" {
+		t.Errorf("application_name = %q, want boilerplate", got)
+	}
+	if replica.ConnConfig.Tracer != primary.ConnConfig.Tracer {
+		t.Error("tracer was not copied from the primary")
+	}
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"time"
+
+	loggerConfig "github.com/C0deNe0/go-boiler/internal/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultSlowQueryThreshold applies when LoggingConfig.SlowQueryTreshold is zero.
+	DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+	maxLoggedSQLLength = 2000
+	explainTimeout     = 5 * time.Second
+)
+
+var (
+	// sqlLiteralRegex matches string literals, positional parameters and numbers;
+	// parameters are kept so the normalized SQL still lines up with the args.
+	sqlLiteralRegex    = regexp.MustCompile(`'(?:[^']|'')*'|\$\d+|\b\d+(?:\.\d+)?\b`)
+	sqlWhitespaceRegex = regexp.MustCompile(`\s+`)
+
+	// explainableRegex limits EXPLAIN to statements it accepts.
+	explainableRegex = regexp.MustCompile(`(?i)^\s*(SELECT|WITH|INSERT|UPDATE|DELETE)\b`)
+)
+
+type slowQueryContextKey struct{}
+
+type slowQueryStart struct {
+	startedAt time.Time
+	sql       string
+	args      []any
+}
+
+type skipExplainContextKey struct{}
+
+// SlowQueryTracer logs every query that runs longer than Threshold with its
+// normalized SQL, the types of its arguments (never their values) and the
+// request, user and trace IDs carried by the query context.
+type SlowQueryTracer struct {
+	Threshold time.Duration
+
+	// ExplainRate is the fraction of slow queries, between 0 and 1, whose plan
+	// is logged as well. Plans are fetched with a plain EXPLAIN through Explainer.
+	ExplainRate float64
+	Explainer   Querier
+
+	logger *zerolog.Logger
+}
+
+// NewSlowQueryTracer creates a tracer; a zero threshold means DefaultSlowQueryThreshold.
+func NewSlowQueryTracer(logger *zerolog.Logger, threshold time.Duration, explainRate float64) *SlowQueryTracer {
+	if threshold == 0 {
+		threshold = DefaultSlowQueryThreshold
+	}
+
+	return &SlowQueryTracer{
+		Threshold:   threshold,
+		ExplainRate: explainRate,
+		logger:      logger,
+	}
+}
+
+func (t *SlowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, slowQueryContextKey{}, &slowQueryStart{
+		startedAt: time.Now(),
+		sql:       data.SQL,
+		args:      data.Args,
+	})
+}
+
+func (t *SlowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(slowQueryContextKey{}).(*slowQueryStart)
+	if !ok {
+		return
+	}
+
+	duration := time.Since(start.startedAt)
+	if duration < t.Threshold {
+		return
+	}
+
+	event := t.logger.Warn().
+		Str("component", "database").
+		Dur("duration", duration).
+		Dur("threshold", t.Threshold).
+		Str("sql", NormalizeSQL(start.sql)).
+		Strs("arg_types", argTypes(start.args)).
+		Str("command_tag", data.CommandTag.String())
+	event = withContextIDs(ctx, event)
+	if data.Err != nil {
+		event = event.Err(data.Err)
+	}
+	event.Msg("slow query")
+
+	if t.shouldExplain(ctx, start.sql) {
+		go t.explain(context.WithoutCancel(ctx), start)
+	}
+}
+
+func (t *SlowQueryTracer) shouldExplain(ctx context.Context, sql string) bool {
+	if t.Explainer == nil || t.ExplainRate <= 0 || ctx.Value(skipExplainContextKey{}) != nil {
+		return false
+	}
+	if !explainableRegex.MatchString(sql) {
+		return false
+	}
+	return rand.Float64() < t.ExplainRate
+}
+
+func (t *SlowQueryTracer) explain(ctx context.Context, start *slowQueryStart) {
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, skipExplainContextKey{}, true), explainTimeout)
+	defer cancel()
+
+	rows, err := t.Explainer.Query(ctx, "EXPLAIN "+start.sql, start.args...)
+	if err != nil {
+		t.logger.Warn().Err(err).Str("sql", NormalizeSQL(start.sql)).Msg("could not explain slow query")
+		return
+	}
+
+	plan, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.logger.Warn().Err(err).Str("sql", NormalizeSQL(start.sql)).Msg("could not explain slow query")
+		return
+	}
+
+	event := t.logger.Info().
+		Str("component", "database").
+		Str("sql", NormalizeSQL(start.sql)).
+		Str("plan", strings.Join(plan, "\n"))
+	withContextIDs(ctx, event).Msg("slow query plan")
+}
+
+// NormalizeSQL collapses whitespace and replaces inline literals with "?",
+// so that logged statements group together and carry no data.
+func NormalizeSQL(sql string) string {
+	normalized := sqlLiteralRegex.ReplaceAllStringFunc(sql, func(match string) string {
+		if strings.HasPrefix(match, "$") {
+			return match
+		}
+		return "?"
+	})
+	normalized = strings.TrimSpace(sqlWhitespaceRegex.ReplaceAllString(normalized, " "))
+
+	if len(normalized) > maxLoggedSQLLength {
+		normalized = normalized[:maxLoggedSQLLength] + "..."
+	}
+	return normalized
+}
+
+func argTypes(args []any) []string {
+	types := make([]string, 0, len(args))
+	for _, arg := range args {
+		types = append(types, fmt.Sprintf("%T", arg))
+	}
+	return types
+}
+
+func withContextIDs(ctx context.Context, event *zerolog.Event) *zerolog.Event {
+	if requestID := loggerConfig.RequestIDFromContext(ctx); requestID != "" {
+		event = event.Str("request_id", requestID)
+	}
+	if userID := loggerConfig.UserIDFromContext(ctx); userID != "" {
+		event = event.Str("user_id", userID)
+	}
+	if txn := newrelic.FromContext(ctx); txn != nil {
+		event = event.Str("trace.id", txn.GetTraceMetadata().TraceID)
+	}
+	return event
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TracerChain fans every pgx trace hook out to the tracers that implement it.
+// Start hooks run in registration order, each receiving the context returned
+// by the previous one; end hooks run in reverse order with the final context.
+type TracerChain struct {
+	query    []pgx.QueryTracer
+	batch    []pgx.BatchTracer
+	copyFrom []pgx.CopyFromTracer
+	connect  []pgx.ConnectTracer
+	prepare  []pgx.PrepareTracer
+}
+
+var (
+	_ pgx.QueryTracer    = (*TracerChain)(nil)
+	_ pgx.BatchTracer    = (*TracerChain)(nil)
+	_ pgx.CopyFromTracer = (*TracerChain)(nil)
+	_ pgx.ConnectTracer  = (*TracerChain)(nil)
+	_ pgx.PrepareTracer  = (*TracerChain)(nil)
+)
+
+// NewTracerChain registers each tracer for every pgx tracer interface it
+// implements. It fails when a tracer implements none of them.
+func NewTracerChain(tracers ...any) (*TracerChain, error) {
+	chain := &TracerChain{}
+
+	for _, tracer := range tracers {
+		registered := false
+
+		if t, ok := tracer.(pgx.QueryTracer); ok {
+			chain.query = append(chain.query, t)
+			registered = true
+		}
+		if t, ok := tracer.(pgx.BatchTracer); ok {
+			chain.batch = append(chain.batch, t)
+			registered = true
+		}
+		if t, ok := tracer.(pgx.CopyFromTracer); ok {
+			chain.copyFrom = append(chain.copyFrom, t)
+			registered = true
+		}
+		if t, ok := tracer.(pgx.ConnectTracer); ok {
+			chain.connect = append(chain.connect, t)
+			registered = true
+		}
+		if t, ok := tracer.(pgx.PrepareTracer); ok {
+			chain.prepare = append(chain.prepare, t)
+			registered = true
+		}
+
+		if !registered {
+			return nil, fmt.Errorf("%T does not implement any pgx tracer interface", tracer)
+		}
+	}
+
+	return chain, nil
+}
+
+func (c *TracerChain) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for _, t := range c.query {
+		ctx = t.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (c *TracerChain) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for i := len(c.query) - 1; i >= 0; i-- {
+		c.query[i].TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+func (c *TracerChain) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	for _, t := range c.batch {
+		ctx = t.TraceBatchStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (c *TracerChain) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	for _, t := range c.batch {
+		t.TraceBatchQuery(ctx, conn, data)
+	}
+}
+
+func (c *TracerChain) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	for i := len(c.batch) - 1; i >= 0; i-- {
+		c.batch[i].TraceBatchEnd(ctx, conn, data)
+	}
+}
+
+func (c *TracerChain) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	for _, t := range c.copyFrom {
+		ctx = t.TraceCopyFromStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (c *TracerChain) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	for i := len(c.copyFrom) - 1; i >= 0; i-- {
+		c.copyFrom[i].TraceCopyFromEnd(ctx, conn, data)
+	}
+}
+
+func (c *TracerChain) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	for _, t := range c.connect {
+		ctx = t.TraceConnectStart(ctx, data)
+	}
+	return ctx
+}
+
+func (c *TracerChain) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	for i := len(c.connect) - 1; i >= 0; i-- {
+		c.connect[i].TraceConnectEnd(ctx, data)
+	}
+}
+
+func (c *TracerChain) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	for _, t := range c.prepare {
+		ctx = t.TracePrepareStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (c *TracerChain) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+	for i := len(c.prepare) - 1; i >= 0; i-- {
+		c.prepare[i].TracePrepareEnd(ctx, conn, data)
+	}
+}
@@ -0,0 +1,196 @@
+package database_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/C0deNe0/go-boiler/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+type hookKey struct{}
+
+// recordingTracer implements every pgx tracer interface and records each hook
+// it receives together with the hooks that ran before it in the context.
+type recordingTracer struct {
+	name  string
+	calls *[]string
+}
+
+func (r *recordingTracer) start(ctx context.Context, hook string) context.Context {
+	*r.calls = append(*r.calls, r.name+"."+hook)
+	seen, _ := ctx.Value(hookKey{}).([]string)
+	return context.WithValue(ctx, hookKey{}, append(slices.Clone(seen), r.name))
+}
+
+func (r *recordingTracer) end(hook string) {
+	*r.calls = append(*r.calls, r.name+"."+hook)
+}
+
+func (r *recordingTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return r.start(ctx, "QueryStart")
+}
+
+func (r *recordingTracer) TraceQueryEnd(_ context.Context, _ *pgx.Conn, _ pgx.TraceQueryEndData) {
+	r.end("QueryEnd")
+}
+
+func (r *recordingTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return r.start(ctx, "BatchStart")
+}
+
+func (r *recordingTracer) TraceBatchQuery(_ context.Context, _ *pgx.Conn, _ pgx.TraceBatchQueryData) {
+	r.end("BatchQuery")
+}
+
+func (r *recordingTracer) TraceBatchEnd(_ context.Context, _ *pgx.Conn, _ pgx.TraceBatchEndData) {
+	r.end("BatchEnd")
+}
+
+func (r *recordingTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceCopyFromStartData) context.Context {
+	return r.start(ctx, "CopyFromStart")
+}
+
+func (r *recordingTracer) TraceCopyFromEnd(_ context.Context, _ *pgx.Conn, _ pgx.TraceCopyFromEndData) {
+	r.end("CopyFromEnd")
+}
+
+func (r *recordingTracer) TraceConnectStart(ctx context.Context, _ pgx.TraceConnectStartData) context.Context {
+	return r.start(ctx, "ConnectStart")
+}
+
+func (r *recordingTracer) TraceConnectEnd(_ context.Context, _ pgx.TraceConnectEndData) {
+	r.end("ConnectEnd")
+}
+
+func (r *recordingTracer) TracePrepareStart(ctx context.Context, _ *pgx.Conn, _ pgx.TracePrepareStartData) context.Context {
+	return r.start(ctx, "PrepareStart")
+}
+
+func (r *recordingTracer) TracePrepareEnd(_ context.Context, _ *pgx.Conn, _ pgx.TracePrepareEndData) {
+	r.end("PrepareEnd")
+}
+
+// connectOnlyTracer implements nothing but pgx.ConnectTracer.
+type connectOnlyTracer struct {
+	calls *[]string
+}
+
+func (c *connectOnlyTracer) TraceConnectStart(ctx context.Context, _ pgx.TraceConnectStartData) context.Context {
+	*c.calls = append(*c.calls, "connect-only.ConnectStart")
+	return ctx
+}
+
+func (c *connectOnlyTracer) TraceConnectEnd(_ context.Context, _ pgx.TraceConnectEndData) {
+	*c.calls = append(*c.calls, "connect-only.ConnectEnd")
+}
+
+func newChain(t *testing.T, tracers ...any) *database.TracerChain {
+	t.Helper()
+
+	chain, err := database.NewTracerChain(tracers...)
+	if err != nil {
+		t.Fatalf("NewTracerChain: %v", err)
+	}
+	return chain
+}
+
+func TestTracerChainCallsEveryHook(t *testing.T) {
+	var calls []string
+	chain := newChain(t,
+		&recordingTracer{name: "a", calls: &calls},
+		&recordingTracer{name: "b", calls: &calls},
+	)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		run  func() context.Context
+		want []string
+	}{
+		{
+			name: "query",
+			run: func() context.Context {
+				ctx := chain.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{})
+				chain.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+				return ctx
+			},
+			want: []string{"a.QueryStart", "b.QueryStart", "b.QueryEnd", "a.QueryEnd"},
+		},
+		{
+			name: "batch",
+			run: func() context.Context {
+				ctx := chain.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{})
+				chain.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{})
+				chain.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+				return ctx
+			},
+			want: []string{"a.BatchStart", "b.BatchStart", "a.BatchQuery", "b.BatchQuery", "b.BatchEnd", "a.BatchEnd"},
+		},
+		{
+			name: "copy from",
+			run: func() context.Context {
+				ctx := chain.TraceCopyFromStart(ctx, nil, pgx.TraceCopyFromStartData{})
+				chain.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{})
+				return ctx
+			},
+			want: []string{"a.CopyFromStart", "b.CopyFromStart", "b.CopyFromEnd", "a.CopyFromEnd"},
+		},
+		{
+			name: "connect",
+			run: func() context.Context {
+				ctx := chain.TraceConnectStart(ctx, pgx.TraceConnectStartData{})
+				chain.TraceConnectEnd(ctx, pgx.TraceConnectEndData{})
+				return ctx
+			},
+			want: []string{"a.ConnectStart", "b.ConnectStart", "b.ConnectEnd", "a.ConnectEnd"},
+		},
+		{
+			name: "prepare",
+			run: func() context.Context {
+				ctx := chain.TracePrepareStart(ctx, nil, pgx.TracePrepareStartData{})
+				chain.TracePrepareEnd(ctx, nil, pgx.TracePrepareEndData{})
+				return ctx
+			},
+			want: []string{"a.PrepareStart", "b.PrepareStart", "b.PrepareEnd", "a.PrepareEnd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+
+			ctx := tt.run()
+
+			if !slices.Equal(calls, tt.want) {
+				t.Errorf("hooks = %v, want %v", calls, tt.want)
+			}
+
+			// each start hook must see the context returned by the previous one
+			if seen, _ := ctx.Value(hookKey{}).([]string); !slices.Equal(seen, []string{"a", "b"}) {
+				t.Errorf("context passed through %v, want [a b]", seen)
+			}
+		})
+	}
+}
+
+func TestTracerChainSkipsUnimplementedHooks(t *testing.T) {
+	var calls []string
+	chain := newChain(t, &connectOnlyTracer{calls: &calls})
+	ctx := context.Background()
+
+	chain.TraceQueryEnd(chain.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{}), nil, pgx.TraceQueryEndData{})
+	chain.TraceConnectEnd(chain.TraceConnectStart(ctx, pgx.TraceConnectStartData{}), pgx.TraceConnectEndData{})
+
+	want := []string{"connect-only.ConnectStart", "connect-only.ConnectEnd"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("hooks = %v, want %v", calls, want)
+	}
+}
+
+func TestNewTracerChainRejectsNonTracers(t *testing.T) {
+	if _, err := database.NewTracerChain(struct{}{}); err == nil {
+		t.Fatal("expected an error for a value that implements no tracer interface")
+	}
+}
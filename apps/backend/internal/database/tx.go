@@ -0,0 +1,229 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/sqlerr"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// DefaultTxMaxRetries is how often WithTx retries after a deadlock or
+	// serialization failure when TxOptions.MaxRetries is zero.
+	DefaultTxMaxRetries = 3
+
+	// DefaultTxRetryBackoff is the delay before the first retry; it doubles
+	// after every attempt.
+	DefaultTxRetryBackoff = 50 * time.Millisecond
+)
+
+// TxOptions configures a transaction started by WithTx.
+type TxOptions struct {
+	IsoLevel pgx.TxIsoLevel
+	ReadOnly bool
+
+	// MaxRetries is the number of retries after a deadlock or serialization
+	// failure. Zero means DefaultTxMaxRetries, a negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry. Zero means DefaultTxRetryBackoff.
+	RetryBackoff time.Duration
+}
+
+// Querier is satisfied by both the pool and a transaction, so repositories
+// can run the same statements inside and outside of WithTx.
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+type txContextKey struct{}
+
+// TxFromContext returns the transaction started by WithTx, if any.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// Querier returns the transaction carried by ctx, or the primary pool when
+// there is none. Use Reader for work that may run on a replica.
+func (db *Database) Querier(ctx context.Context) Querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return primaryQuerier{db.Pool}
+}
+
+// primaryQuerier runs statements on the primary pool and marks the session
+// of their context as written when they may have written.
+type primaryQuerier struct {
+	*pgxpool.Pool
+}
+
+func (q primaryQuerier) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	tag, err := q.Pool.Exec(ctx, sql, arguments...)
+	if err == nil && mayWrite(sql) {
+		MarkWritten(ctx)
+	}
+	return tag, err
+}
+
+func (q primaryQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := q.Pool.Query(ctx, sql, args...)
+	if err == nil && mayWrite(sql) {
+		MarkWritten(ctx)
+	}
+	return rows, err
+}
+
+func (q primaryQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	row := q.Pool.QueryRow(ctx, sql, args...)
+	if mayWrite(sql) {
+		MarkWritten(ctx)
+	}
+	return row
+}
+
+func (q primaryQuerier) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	if slices.ContainsFunc(b.QueuedQueries, func(query *pgx.QueuedQuery) bool { return mayWrite(query.SQL) }) {
+		MarkWritten(ctx)
+	}
+	return q.Pool.SendBatch(ctx, b)
+}
+
+func (q primaryQuerier) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	n, err := q.Pool.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	if err == nil {
+		MarkWritten(ctx)
+	}
+	return n, err
+}
+
+// writingSelectRegex matches what makes a SELECT write or lock: row locking
+// clauses and sequence updates.
+var writingSelectRegex = regexp.MustCompile(`(?i)\bFOR\s+(?:NO\s+KEY\s+UPDATE|UPDATE|KEY\s+SHARE|SHARE)\b|\b(?:nextval|setval)\s*\(`)
+
+// mayWrite reports whether sql is anything but a plain SELECT. Statements
+// starting with WITH count as writes, since CTEs can modify data, and so do
+// SELECTs with locking clauses or sequence updates. Other functions that
+// write are not detected; see MarkWritten.
+func mayWrite(sql string) bool {
+	sql = strings.TrimSpace(sql)
+	if len(sql) < len("SELECT") || !strings.EqualFold(sql[:len("SELECT")], "SELECT") {
+		return true
+	}
+	return writingSelectRegex.MatchString(sql)
+}
+
+// WithTx runs fn in a transaction that is committed when fn returns nil and
+// rolled back otherwise. The transaction is carried in the context passed to
+// fn, so repositories using Querier join it without receiving tx.
+//
+// When ctx already carries a transaction, fn runs in a savepoint of it and
+// opts are ignored; retries are left to the outermost WithTx. Otherwise the
+// whole transaction, fn included, is retried with exponential backoff when it
+// fails with a deadlock or serialization failure, so fn must not have side
+// effects outside the database.
+//
+// Read-only transactions run on a healthy replica when one is available,
+// except serializable ones, which Postgres does not allow on a hot standby.
+func (db *Database) WithTx(ctx context.Context, opts TxOptions, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	if parent, ok := TxFromContext(ctx); ok {
+		return runTx(ctx, parent.Begin, fn)
+	}
+
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultTxMaxRetries
+	}
+	backoff := opts.RetryBackoff
+	if backoff == 0 {
+		backoff = DefaultTxRetryBackoff
+	}
+
+	txOptions := pgx.TxOptions{IsoLevel: opts.IsoLevel}
+	if opts.ReadOnly {
+		txOptions.AccessMode = pgx.ReadOnly
+	}
+
+	begin := func(ctx context.Context) (pgx.Tx, error) {
+		if opts.ReadOnly && opts.IsoLevel != pgx.Serializable {
+			return db.readPool(ctx).BeginTx(ctx, txOptions)
+		}
+		return db.Pool.BeginTx(ctx, txOptions)
+	}
+
+	return db.retryTx(ctx, maxRetries, backoff, func() error {
+		err := runTx(ctx, begin, fn)
+		if err == nil && !opts.ReadOnly {
+			MarkWritten(ctx)
+		}
+		return err
+	})
+}
+
+// retryTx runs attempt until it succeeds, fails with an error that is not
+// retryable, or has been retried maxRetries times, backing off exponentially
+// from backoff between attempts.
+func (db *Database) retryTx(ctx context.Context, maxRetries int, backoff time.Duration, attempt func() error) error {
+	for retry := 0; ; retry++ {
+		err := attempt()
+		if err == nil || retry >= maxRetries || !sqlerr.IsRetryable(err) {
+			return err
+		}
+
+		// full jitter keeps competing transactions from retrying in lockstep
+		delay := backoff << retry
+		delay = delay/2 + rand.N(delay/2+1)
+
+		db.log.Warn().Err(err).
+			Int("attempt", retry+1).
+			Dur("backoff", delay).
+			Msg("retrying transaction")
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+func runTx(ctx context.Context, begin func(context.Context) (pgx.Tx, error), fn func(ctx context.Context, tx pgx.Tx) error) (err error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx), tx); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+)
+
+var nopLogger = zerolog.Nop()
+
+func pgError(code string) error {
+	return fmt.Errorf("failed to commit transaction: %w", &pgconn.PgError{Code: code})
+}
+
+func TestRetryTx(t *testing.T) {
+	var (
+		serializationFailure = pgError("40001")
+		deadlock             = pgError("40P01")
+		uniqueViolation      = pgError("23505")
+	)
+
+	tests := []struct {
+		name         string
+		maxRetries   int
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "success", maxRetries: 3, errs: []error{nil}, wantAttempts: 1},
+		{name: "serialization failure then success", maxRetries: 3, errs: []error{serializationFailure, nil}, wantAttempts: 2},
+		{name: "deadlock then success", maxRetries: 3, errs: []error{deadlock, deadlock, nil}, wantAttempts: 3},
+		{
+			name:         "retries exhausted",
+			maxRetries:   2,
+			errs:         []error{serializationFailure, serializationFailure, serializationFailure, nil},
+			wantAttempts: 3,
+			wantErr:      serializationFailure,
+		},
+		{name: "not retryable", maxRetries: 3, errs: []error{uniqueViolation, nil}, wantAttempts: 1, wantErr: uniqueViolation},
+		{name: "plain error", maxRetries: 3, errs: []error{pgx.ErrNoRows, nil}, wantAttempts: 1, wantErr: pgx.ErrNoRows},
+		{name: "retries disabled", maxRetries: -1, errs: []error{serializationFailure, nil}, wantAttempts: 1, wantErr: serializationFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &Database{log: &nopLogger}
+
+			attempts := 0
+			err := db.retryTx(context.Background(), tt.maxRetries, time.Microsecond, func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("retryTx() = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryTxStopsWhenContextIsDone(t *testing.T) {
+	db := &Database{log: &nopLogger}
+	ctx, cancel := context.WithCancel(context.Background())
+	serializationFailure := pgError("40001")
+
+	attempts := 0
+	err := db.retryTx(ctx, 10, time.Hour, func() error {
+		attempts++
+		cancel()
+		return serializationFailure
+	})
+
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, serializationFailure) {
+		t.Errorf("retryTx() = %v, want the failure joined with context.Canceled", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+// fakeTx records the calls WithTx makes on a transaction. Methods it does not
+// override panic through the nil embedded interface.
+type fakeTx struct {
+	pgx.Tx
+	name      string
+	calls     *[]string
+	commitErr error
+}
+
+func (f *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	*f.calls = append(*f.calls, f.name+".savepoint")
+	return &fakeTx{name: f.name + ".sp", calls: f.calls}, nil
+}
+
+func (f *fakeTx) Commit(context.Context) error {
+	*f.calls = append(*f.calls, f.name+".commit")
+	return f.commitErr
+}
+
+func (f *fakeTx) Rollback(context.Context) error {
+	*f.calls = append(*f.calls, f.name+".rollback")
+	return nil
+}
+
+func TestRunTx(t *testing.T) {
+	errFn := errors.New("fn failed")
+	errCommit := errors.New("commit failed")
+
+	tests := []struct {
+		name      string
+		fn        func(ctx context.Context, tx pgx.Tx) error
+		commitErr error
+		wantCalls []string
+		wantErr   error
+	}{
+		{
+			name:      "commits",
+			fn:        func(context.Context, pgx.Tx) error { return nil },
+			wantCalls: []string{"tx.commit"},
+		},
+		{
+			name:      "rolls back on error",
+			fn:        func(context.Context, pgx.Tx) error { return errFn },
+			wantCalls: []string{"tx.rollback"},
+			wantErr:   errFn,
+		},
+		{
+			name:      "commit error",
+			fn:        func(context.Context, pgx.Tx) error { return nil },
+			commitErr: errCommit,
+			wantCalls: []string{"tx.commit"},
+			wantErr:   errCommit,
+		},
+		{
+			name: "nested WithTx uses a savepoint",
+			fn: func(ctx context.Context, _ pgx.Tx) error {
+				// the receiver is unused when ctx carries a transaction
+				return (&Database{}).WithTx(ctx, TxOptions{}, func(context.Context, pgx.Tx) error { return nil })
+			},
+			wantCalls: []string{"tx.savepoint", "tx.sp.commit", "tx.commit"},
+		},
+		{
+			name: "failed savepoint rolls back only the savepoint",
+			fn: func(ctx context.Context, _ pgx.Tx) error {
+				_ = (&Database{}).WithTx(ctx, TxOptions{}, func(context.Context, pgx.Tx) error { return errFn })
+				return nil
+			},
+			wantCalls: []string{"tx.savepoint", "tx.sp.rollback", "tx.commit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			tx := &fakeTx{name: "tx", calls: &calls, commitErr: tt.commitErr}
+			begin := func(context.Context) (pgx.Tx, error) { return tx, nil }
+
+			err := runTx(context.Background(), begin, func(ctx context.Context, got pgx.Tx) error {
+				if carried, ok := TxFromContext(ctx); !ok || carried != got {
+					t.Error("fn's context does not carry its transaction")
+				}
+				return tt.fn(ctx, got)
+			})
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("runTx() = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRunTxRollsBackOnPanic(t *testing.T) {
+	var calls []string
+	tx := &fakeTx{name: "tx", calls: &calls}
+	begin := func(context.Context) (pgx.Tx, error) { return tx, nil }
+
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("recovered %v, want the original panic", p)
+		}
+		if !slices.Equal(calls, []string{"tx.rollback"}) {
+			t.Errorf("calls = %q, want a rollback", calls)
+		}
+	}()
+
+	_ = runTx(context.Background(), begin, func(context.Context, pgx.Tx) error { panic("boom") })
+}
+
+func TestRunTxBeginError(t *testing.T) {
+	errBegin := errors.New("no connection")
+	begin := func(context.Context) (pgx.Tx, error) { return nil, errBegin }
+
+	called := false
+	err := runTx(context.Background(), begin, func(context.Context, pgx.Tx) error {
+		called = true
+		return nil
+	})
+
+	if !errors.Is(err, errBegin) {
+		t.Errorf("runTx() = %v, want %v", err, errBegin)
+	}
+	if called {
+		t.Error("fn ran without a transaction")
+	}
+}
+
+func TestMayWrite(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"SELECT 1", false},
+		{"\n\tselect id FROM users WHERE id = $1", false},
+		{"SELECT id FROM job_outbox FOR UPDATE SKIP LOCKED", true},
+		{"select * from users where id = $1 for no key update", true},
+		{"SELECT id FROM users FOR SHARE", true},
+		{"SELECT nextval('invoice_number')", true},
+		{"SELECT setval ('invoice_number', 1)", true},
+		{"SELECT format_update_notice(id) FROM users", false},
+		{"SELECT id FROM users WHERE forupdate = true", false},
+		{"\nINSERT INTO users (id) VALUES ($1) RETURNING id", true},
+		{"UPDATE users SET name = $1 RETURNING *", true},
+		{"DELETE FROM users WHERE id = $1", true},
+		{"WITH moved AS (DELETE FROM a RETURNING *) SELECT * FROM moved", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		if got := mayWrite(tt.sql); got != tt.want {
+			t.Errorf("mayWrite(%q) = %v, want %v", tt.sql, got, tt.want)
+		}
+	}
+}
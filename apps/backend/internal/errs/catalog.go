@@ -0,0 +1,130 @@
+package errs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Stable error codes shared with clients, which should switch on codes
+// rather than messages. Codes derived from an HTTP status are returned by the
+// constructors in types.go when no code is given.
+var (
+	CodeBadRequest           = statusCode(http.StatusBadRequest)
+	CodeUnauthorized         = statusCode(http.StatusUnauthorized)
+	CodeForbidden            = statusCode(http.StatusForbidden)
+	CodeNotFound             = statusCode(http.StatusNotFound)
+	CodeConflict             = statusCode(http.StatusConflict)
+	CodeGone                 = statusCode(http.StatusGone)
+	CodePreconditionFailed   = statusCode(http.StatusPreconditionFailed)
+	CodePayloadTooLarge      = statusCode(http.StatusRequestEntityTooLarge)
+	CodeUnsupportedMediaType = statusCode(http.StatusUnsupportedMediaType)
+	CodeUnprocessableEntity  = statusCode(http.StatusUnprocessableEntity)
+	CodePreconditionRequired = statusCode(http.StatusPreconditionRequired)
+	CodeTooManyRequests      = statusCode(http.StatusTooManyRequests)
+	CodeInternalServerError  = statusCode(http.StatusInternalServerError)
+	CodeServiceUnavailable   = statusCode(http.StatusServiceUnavailable)
+	CodeGatewayTimeout       = statusCode(http.StatusGatewayTimeout)
+)
+
+// CodeVersionConflict is returned by writes that lost a race with a
+// concurrent write to the same row; see NewVersionConflictError.
+var CodeVersionConflict = Register("VERSION_CONFLICT", http.StatusConflict,
+	"The record was changed by someone else, reload it and try again")
+
+// CatalogEntry documents an application error code.
+type CatalogEntry struct {
+	Code string `json:"code"`
+	// Status is the HTTP status the code is returned with; zero when it
+	// depends on the error, as for registered database constraints.
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message"`
+}
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = map[string]CatalogEntry{}
+)
+
+func init() {
+	for _, status := range []int{
+		http.StatusBadRequest,
+		http.StatusUnauthorized,
+		http.StatusForbidden,
+		http.StatusNotFound,
+		http.StatusConflict,
+		http.StatusGone,
+		http.StatusPreconditionFailed,
+		http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType,
+		http.StatusUnprocessableEntity,
+		http.StatusPreconditionRequired,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	} {
+		Register(statusCode(status), status, http.StatusText(status))
+	}
+}
+
+func statusCode(status int) string {
+	return MakeUpperCaseWithUnderscores(http.StatusText(status))
+}
+
+// Register adds code to the catalog with its status and default message and
+// returns code, so packages can declare their codes as variables:
+//
+//	var CodeEmailTaken = errs.Register("EMAIL_TAKEN", http.StatusConflict, "Email is already taken")
+//
+// Registering a code again with the same status and message is a no-op; with
+// a different one Register panics, since codes are part of the public API.
+func Register(code string, status int, message string) string {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	entry := CatalogEntry{Code: code, Status: status, Message: message}
+	if existing, ok := catalog[code]; ok && existing != entry {
+		panic(fmt.Sprintf("errs: code %s registered twice, as %d %q and %d %q",
+			code, existing.Status, existing.Message, status, message))
+	}
+	catalog[code] = entry
+	return code
+}
+
+// Lookup returns the catalog entry for code.
+func Lookup(code string) (CatalogEntry, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	entry, ok := catalog[code]
+	return entry, ok
+}
+
+// Catalog returns every registered code, sorted by code. It is not the
+// complete set of codes: database errors also return codes derived from the
+// table, such as USER_ALREADY_EXISTS, so clients must accept unknown codes.
+func Catalog() []CatalogEntry {
+	catalogMu.RLock()
+	entries := make([]CatalogEntry, 0, len(catalog))
+	for _, entry := range catalog {
+		entries = append(entries, entry)
+	}
+	catalogMu.RUnlock()
+
+	slices.SortFunc(entries, func(a, b CatalogEntry) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+	return entries
+}
+
+// WriteCatalog writes the catalog as indented JSON for the frontend and the
+// OpenAPI spec.
+func WriteCatalog(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Catalog())
+}
@@ -0,0 +1,44 @@
+package errs_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/C0deNe0/go-boiler/internal/errs"
+)
+
+func TestRegister(t *testing.T) {
+	code := errs.Register("CATALOG_TEST_CODE", http.StatusConflict, "Catalog test")
+
+	entry, ok := errs.Lookup(code)
+	if !ok || entry.Status != http.StatusConflict || entry.Message != "Catalog test" {
+		t.Fatalf("Lookup(%s) = %+v, %t", code, entry, ok)
+	}
+
+	// the same entry again is allowed
+	errs.Register(code, http.StatusConflict, "Catalog test")
+
+	tests := []struct {
+		name    string
+		status  int
+		message string
+	}{
+		{name: "different status", status: http.StatusBadRequest, message: "Catalog test"},
+		{name: "different message", status: http.StatusConflict, message: "Another message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("registering a different entry for a code did not panic")
+				}
+			}()
+			errs.Register(code, tt.status, tt.message)
+		})
+	}
+
+	if entry, _ := errs.Lookup(code); entry.Status != http.StatusConflict || entry.Message != "Catalog test" {
+		t.Errorf("entry after rejected registrations = %+v", entry)
+	}
+}
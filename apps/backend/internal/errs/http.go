@@ -1,10 +1,17 @@
 package errs
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type FieldError struct {
 	Field string `json:"field"`
 	Error string `json:"error"`
+
+	// Key and Args translate Error; see Localize
+	Key  string `json:"-"`
+	Args []any  `json:"-"`
 }
 
 type ActionType string
@@ -29,6 +36,13 @@ type HTTPError struct {
 	Errors []FieldError `json:"errors"`
 
 	Action *Action `json:"action"`
+
+	// RetryAfter is sent as the Retry-After header when set
+	RetryAfter time.Duration `json:"-"`
+
+	// MessageKey and MessageArgs translate Message; see Localize
+	MessageKey  string `json:"-"`
+	MessageArgs []any  `json:"-"`
 }
 
 func (e *HTTPError) Error() string {
@@ -42,12 +56,42 @@ func (e *HTTPError) Is(target error) bool {
 
 func (e *HTTPError) WithMessage(message string) *HTTPError {
 	return &HTTPError{
-		Code:     e.Code,
-		Message:  message,
-		Status:   e.Status,
-		Override: e.Override,
-		Errors:   e.Errors,
-		Action:   e.Action,
+		Code:       e.Code,
+		Message:    message,
+		Status:     e.Status,
+		Override:   e.Override,
+		Errors:     e.Errors,
+		Action:     e.Action,
+		RetryAfter: e.RetryAfter,
+	}
+}
+
+// WithMessageKey sets the key and fmt-style args that translate the message.
+func (e *HTTPError) WithMessageKey(key string, args ...any) *HTTPError {
+	return &HTTPError{
+		Code:        e.Code,
+		Message:     e.Message,
+		Status:      e.Status,
+		Override:    e.Override,
+		Errors:      e.Errors,
+		Action:      e.Action,
+		RetryAfter:  e.RetryAfter,
+		MessageKey:  key,
+		MessageArgs: args,
+	}
+}
+
+func (e *HTTPError) WithRetryAfter(retryAfter time.Duration) *HTTPError {
+	return &HTTPError{
+		Code:        e.Code,
+		Message:     e.Message,
+		Status:      e.Status,
+		Override:    e.Override,
+		Errors:      e.Errors,
+		Action:      e.Action,
+		RetryAfter:  retryAfter,
+		MessageKey:  e.MessageKey,
+		MessageArgs: e.MessageArgs,
 	}
 }
 
@@ -0,0 +1,32 @@
+package errs
+
+import (
+	"github.com/C0deNe0/go-boiler/internal/lib/i18n"
+	"golang.org/x/text/language"
+)
+
+// Localize returns a copy of e with the message and field errors translated
+// into locale. A message without a key is translated by its code when it is
+// still the catalog's default message for that code. Untranslated text is
+// kept as is, so English is the last step of the fallback chain.
+func (e *HTTPError) Localize(locale language.Tag) *HTTPError {
+	key, args := e.MessageKey, e.MessageArgs
+	if key == "" {
+		if entry, ok := Lookup(e.Code); ok && entry.Message == e.Message {
+			key = e.Code
+		}
+	}
+
+	localized := *e
+	localized.Message = i18n.Translate(locale, key, e.Message, args...)
+
+	if len(e.Errors) > 0 {
+		localized.Errors = make([]FieldError, len(e.Errors))
+		for i, fieldError := range e.Errors {
+			fieldError.Error = i18n.Translate(locale, fieldError.Key, fieldError.Error, fieldError.Args...)
+			localized.Errors[i] = fieldError
+		}
+	}
+
+	return &localized
+}
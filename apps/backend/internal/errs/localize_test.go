@@ -0,0 +1,103 @@
+package errs_test
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/C0deNe0/go-boiler/internal/errs"
+	"golang.org/x/text/language"
+)
+
+func TestHTTPErrorLocalize(t *testing.T) {
+	code := "CUSTOM_CODE"
+
+	tests := []struct {
+		name        string
+		err         *errs.HTTPError
+		locale      language.Tag
+		wantMessage string
+		wantErrors  []string
+	}{
+		{
+			name:        "message key",
+			err:         errs.NewNotFoundError("Route not found", false, nil).WithMessageKey("error.route_not_found"),
+			locale:      language.Spanish,
+			wantMessage: "Ruta no encontrada",
+		},
+		{
+			name:        "message key in a regional locale",
+			err:         errs.NewNotFoundError("Route not found", false, nil).WithMessageKey("error.route_not_found"),
+			locale:      language.MustParse("es-MX"),
+			wantMessage: "Ruta no encontrada",
+		},
+		{
+			name:        "default message translated by code",
+			err:         errs.NewNotFoundError(http.StatusText(http.StatusNotFound), false, nil),
+			locale:      language.Spanish,
+			wantMessage: "No encontrado",
+		},
+		{
+			name:        "custom message without key",
+			err:         errs.NewNotFoundError("Project not found", true, nil),
+			locale:      language.Spanish,
+			wantMessage: "Project not found",
+		},
+		{
+			name:        "unknown code",
+			err:         errs.NewConflictError("Custom conflict", true, &code),
+			locale:      language.Spanish,
+			wantMessage: "Custom conflict",
+		},
+		{
+			name:        "default locale",
+			err:         errs.NewNotFoundError("Route not found", false, nil).WithMessageKey("error.route_not_found"),
+			locale:      language.English,
+			wantMessage: "Route not found",
+		},
+		{
+			name: "field errors",
+			err: errs.NewBadRequestError("Validation failed", true, nil, []errs.FieldError{
+				{Field: "name", Error: "is required", Key: "validation.required"},
+				{Field: "bio", Error: "must not exceed 10 characters", Key: "validation.max_length", Args: []any{"10"}},
+				{Field: "other", Error: "is odd"},
+			}, nil).WithMessageKey("validation.failed"),
+			locale:      language.Spanish,
+			wantMessage: "La validación falló",
+			wantErrors:  []string{"es obligatorio", "no debe superar los 10 caracteres", "is odd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := *tt.err
+			originalErrors := slices.Clone(tt.err.Errors)
+
+			got := tt.err.Localize(tt.locale)
+
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			var gotErrors []string
+			for _, fieldError := range got.Errors {
+				gotErrors = append(gotErrors, fieldError.Error)
+			}
+			if !slices.Equal(gotErrors, tt.wantErrors) {
+				t.Errorf("Errors = %q, want %q", gotErrors, tt.wantErrors)
+			}
+			if got.Code != tt.err.Code || got.Status != tt.err.Status {
+				t.Errorf("code and status changed to %s %d", got.Code, got.Status)
+			}
+
+			// the error may be shared, so Localize must not modify it
+			if tt.err.Message != original.Message {
+				t.Errorf("original message changed to %q", tt.err.Message)
+			}
+			for i, fieldError := range tt.err.Errors {
+				if fieldError.Error != originalErrors[i].Error {
+					t.Errorf("original field error changed to %q", fieldError.Error)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package errs
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MIMEApplicationProblemJSON is the media type of RFC 9457 problem details.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Problem is an RFC 9457 problem details object. The HTTPError fields that
+// have no standard member are kept as extension members, so clients of the
+// HTTPError shape find code, override, errors and action under the same names.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	Code     string       `json:"code"`
+	Override bool         `json:"override"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	Action   *Action      `json:"action,omitempty"`
+}
+
+// NewProblem converts e into problem details. The type is typeBaseURL
+// followed by the error code in kebab case, or "about:blank" when
+// typeBaseURL is empty; instance identifies the occurrence, such as the
+// request ID.
+func NewProblem(e *HTTPError, typeBaseURL, instance string) *Problem {
+	problemType := "about:blank"
+	if typeBaseURL != "" && e.Code != "" {
+		problemType = strings.TrimSuffix(typeBaseURL, "/") + "/" +
+			strings.ToLower(strings.ReplaceAll(e.Code, "_", "-"))
+	}
+
+	return &Problem{
+		Type:     problemType,
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Override: e.Override,
+		Errors:   e.Errors,
+		Action:   e.Action,
+	}
+}
@@ -2,6 +2,7 @@ package errs
 
 import (
 	"net/http"
+	"time"
 )
 
 func NewUnauthorizedError(message string, override bool) *HTTPError {
@@ -54,6 +55,142 @@ func NewNotFoundError(message string, override bool, code *string) *HTTPError {
 	}
 }
 
+func NewConflictError(message string, override bool, code *string) *HTTPError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusConflict))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HTTPError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusConflict,
+		Override: override,
+	}
+}
+
+func NewGoneError(message string, override bool, code *string) *HTTPError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusGone))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HTTPError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusGone,
+		Override: override,
+	}
+}
+
+func NewPreconditionFailedError(message string, override bool, code *string) *HTTPError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusPreconditionFailed))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HTTPError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusPreconditionFailed,
+		Override: override,
+	}
+}
+
+func NewPreconditionRequiredError(message string, override bool) *HTTPError {
+	return &HTTPError{
+		Code:     MakeUpperCaseWithUnderscores(http.StatusText(http.StatusPreconditionRequired)),
+		Message:  message,
+		Status:   http.StatusPreconditionRequired,
+		Override: override,
+	}
+}
+
+func NewPayloadTooLargeError(message string, override bool, code *string) *HTTPError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusRequestEntityTooLarge))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HTTPError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusRequestEntityTooLarge,
+		Override: override,
+	}
+}
+
+func NewUnsupportedMediaTypeError(message string, override bool) *HTTPError {
+	return &HTTPError{
+		Code:     MakeUpperCaseWithUnderscores(http.StatusText(http.StatusUnsupportedMediaType)),
+		Message:  message,
+		Status:   http.StatusUnsupportedMediaType,
+		Override: override,
+	}
+}
+
+func NewUnprocessableEntityError(message string, override bool, code *string, errors []FieldError) *HTTPError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusUnprocessableEntity))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HTTPError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusUnprocessableEntity,
+		Override: override,
+		Errors:   errors,
+	}
+}
+
+// NewTooManyRequestsError tells the client to wait retryAfter before trying
+// again; zero omits the Retry-After header.
+func NewTooManyRequestsError(message string, override bool, retryAfter time.Duration) *HTTPError {
+	return &HTTPError{
+		Code:       MakeUpperCaseWithUnderscores(http.StatusText(http.StatusTooManyRequests)),
+		Message:    message,
+		Status:     http.StatusTooManyRequests,
+		Override:   override,
+		RetryAfter: retryAfter,
+	}
+}
+
+func NewServiceUnavailableError(message string, override bool, code *string) *HTTPError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusServiceUnavailable))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HTTPError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusServiceUnavailable,
+		Override: override,
+	}
+}
+
+func NewGatewayTimeoutError(message string, override bool, code *string) *HTTPError {
+	formattedCode := MakeUpperCaseWithUnderscores(http.StatusText(http.StatusGatewayTimeout))
+
+	if code != nil {
+		formattedCode = *code
+	}
+
+	return &HTTPError{
+		Code:     formattedCode,
+		Message:  message,
+		Status:   http.StatusGatewayTimeout,
+		Override: override,
+	}
+}
+
 func NewInternalServerError() *HTTPError {
 	return &HTTPError{
 		Code:     MakeUpperCaseWithUnderscores(http.StatusText(http.StatusInternalServerError)),
@@ -66,3 +203,11 @@ func NewInternalServerError() *HTTPError {
 func ValidationError(err error) *HTTPError {
 	return NewBadRequestError("validation failed: "+err.Error(), false, nil, nil, nil)
 }
+
+// NewVersionConflictError reports a write that lost a race with a concurrent
+// write to the same row, with the catalog message of CodeVersionConflict.
+func NewVersionConflictError() *HTTPError {
+	code := CodeVersionConflict
+	entry, _ := Lookup(code)
+	return NewConflictError(entry.Message, true, &code)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/C0deNe0/go-boiler/internal/middlerware"
@@ -40,6 +41,12 @@ type JSONResponseHandler struct {
 }
 
 func (h JSONResponseHandler) Handle(c echo.Context, result interface{}) error {
+	if versioned, ok := result.(Versioned); ok {
+		SetETag(c, versioned.GetVersion())
+		if notModified(c, versioned.GetVersion()) {
+			return c.NoContent(http.StatusNotModified)
+		}
+	}
 	return c.JSON(h.status, result)
 }
 
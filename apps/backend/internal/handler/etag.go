@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/C0deNe0/go-boiler/internal/errs"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// Versioned is implemented by responses embedding model.BaseWithVersion;
+// Handle sends their version as the ETag.
+type Versioned interface {
+	GetVersion() int64
+}
+
+// ETag formats a row version as a strong entity tag.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// SetETag sets the ETag response header to version.
+func SetETag(c echo.Context, version int64) {
+	c.Response().Header().Set(headerETag, ETag(version))
+}
+
+// notModified reports whether a GET request's If-None-Match already names version.
+func notModified(c echo.Context, version int64) bool {
+	if c.Request().Method != http.MethodGet {
+		return false
+	}
+
+	etag := ETag(version)
+	for _, candidate := range strings.Split(c.Request().Header.Get(headerIfNoneMatch), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// IfMatch returns the version named by the If-Match request header. Writes to
+// versioned resources must send it: a missing header fails with 428 and a
+// malformed one with 400. "If-Match: *" matches any current version, which
+// IfMatch reports with anyVersion; the write should then skip the version
+// check, see repository.AnyVersion.
+func IfMatch(c echo.Context) (version int64, anyVersion bool, err error) {
+	header := strings.TrimSpace(c.Request().Header.Get(headerIfMatch))
+	if header == "" {
+		return 0, false, errs.NewPreconditionRequiredError("The If-Match header is required to update this resource", true).
+			WithMessageKey("etag.if_match_required")
+	}
+	if header == "*" {
+		return 0, true, nil
+	}
+
+	version, err = strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || strings.Contains(header, ",") || strings.HasPrefix(header, "W/") {
+		return 0, false, errs.NewBadRequestError("The If-Match header must hold the ETag of the resource", true, nil, nil, nil).
+			WithMessageKey("etag.if_match_invalid")
+	}
+	return version, false, nil
+}
+
+// PreconditionFailed turns a repository version conflict into 412, since the
+// If-Match the client sent no longer matches the stored version.
+func PreconditionFailed(err error) error {
+	var httpErr *errs.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code == errs.CodeVersionConflict {
+		code := errs.CodeVersionConflict
+		return errs.NewPreconditionFailedError(httpErr.Message, httpErr.Override, &code)
+	}
+	return err
+}
@@ -68,6 +68,17 @@ func (h *HealthHandler) CheckHealth(c echo.Context) error {
 		logger.Info().Dur("response_time", time.Since(dbStart)).Msg("database health check passed")
 	}
 
+	// Replicas do not affect overall health; reads fall back to the primary
+	if replicas := h.server.Db.Replicas(); len(replicas) > 0 {
+		checks["database_replicas"] = replicas
+		for _, replica := range replicas {
+			if !replica.Healthy {
+				logger.Warn().Str("replica", replica.Name).Str("error", replica.Error).
+					Dur("lag", replica.Lag).Msg("database replica unhealthy")
+			}
+		}
+	}
+
 	// Database connection metrics are automatically captured by New Relic nrpgx5 integration
 
 	// Check Redis connectivity
@@ -18,7 +18,7 @@ type Client struct {
 
 func NewClient(logger *zerolog.Logger, cfg *config.Config) *Client {
 	return &Client{
-		client: resend.NewClient(cfg.Integration.ResendAPIKey),
+		client: resend.NewClient(cfg.Integration.ResendAPIKey.Value()),
 		logger: logger,
 	}
 }
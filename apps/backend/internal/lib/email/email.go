@@ -1,14 +1,32 @@
 package email
 
-func (c *Client) SendWelcomeEmail(to, firstName string) error {
-	data := map[string]string{
+import "github.com/C0deNe0/go-boiler/internal/lib/i18n"
+
+// SendWelcomeEmail sends the welcome email in locale, falling back to English
+// when the locale is empty or unsupported. The template only lays out the
+// translated strings it is given.
+func (c *Client) SendWelcomeEmail(to, firstName, locale string) error {
+	tag := i18n.Match(locale)
+	t := func(key, fallback string, args ...any) string {
+		return i18n.Translate(tag, key, fallback, args...)
+	}
 
+	data := map[string]string{
+		"Lang":          tag.String(),
 		"UserFirstName": firstName,
+		"Preview":       t("email.welcome.preview", "Welcome to Boilerplate"),
+		"Heading":       t("email.welcome.heading", "Welcome to Boilerplate!"),
+		"Greeting":      t("email.welcome.greeting", "Hi %s,", firstName),
+		"Body":          t("email.welcome.body", "Thank you for joining!"),
+		"GetStarted":    t("email.welcome.get_started", "Get Started"),
+		"SupportPrefix": t("email.welcome.support_prefix", "If you have any questions, feel free to"),
+		"SupportLink":   t("email.welcome.support_link", "contact our support team"),
+		"Copyright":     t("email.welcome.copyright", "All rights reserved."),
 	}
 
 	return c.SendEmail(
 		to,
-		"welcome to BoilerPlate!",
+		t("email.welcome.subject", "welcome to BoilerPlate!"),
 		TemplateWelcome,
 		data,
 	)
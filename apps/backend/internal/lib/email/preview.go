@@ -2,6 +2,15 @@ package email
 
 var PreviewData = map[string]map[string]string{
 	"welcome": {
-		"UserName": "naveen",
+		"Lang":          "en",
+		"UserFirstName": "naveen",
+		"Preview":       "Welcome to Boilerplate",
+		"Heading":       "Welcome to Boilerplate!",
+		"Greeting":      "Hi naveen,",
+		"Body":          "Thank you for joining!",
+		"GetStarted":    "Get Started",
+		"SupportPrefix": "If you have any questions, feel free to",
+		"SupportLink":   "contact our support team",
+		"Copyright":     "All rights reserved.",
 	},
 }
@@ -0,0 +1,304 @@
+package filter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/validation"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/labstack/echo/v4"
+)
+
+// Operator compares a column with the value of a filter parameter.
+type Operator string
+
+const (
+	Eq   Operator = "eq"
+	Ne   Operator = "ne"
+	In   Operator = "in"
+	Gt   Operator = "gt"
+	Gte  Operator = "gte"
+	Lt   Operator = "lt"
+	Lte  Operator = "lte"
+	Like Operator = "like"
+)
+
+var operatorSQL = map[Operator]string{
+	Eq:  "=",
+	Ne:  "<>",
+	Gt:  ">",
+	Gte: ">=",
+	Lt:  "<",
+	Lte: "<=",
+}
+
+// Type is the type filter values are parsed into before they are bound.
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Time
+	UUID
+)
+
+// MaxInValues caps the number of comma-separated values of an "in" filter.
+const MaxInValues = 100
+
+// filterParamRegex matches filter[field] and filter[field][operator].
+var filterParamRegex = regexp.MustCompile(`^filter\[([A-Za-z0-9_]+)\](?:\[([a-z]+)\])?$`)
+
+// Field declares a filterable field of a resource.
+type Field struct {
+	// Column defaults to the field name.
+	Column string
+	Type   Type
+	// Operators defaults to eq and in.
+	Operators []Operator
+}
+
+// Spec declares which fields of a resource can be filtered and sorted.
+// Anything else in the query string is rejected, so only declared columns
+// ever reach the SQL.
+type Spec struct {
+	Fields map[string]Field
+
+	// Sortable maps sort parameter names to columns.
+	Sortable map[string]string
+}
+
+// Condition is a single parsed filter.
+type Condition struct {
+	Column   string
+	Operator Operator
+	// Values holds one value, or several for In.
+	Values []any
+}
+
+// Query is the parsed filter and sort of a list request.
+type Query struct {
+	Conditions []Condition
+
+	// Sort lists columns in order, each prefixed with "-" when descending,
+	// in the form Repository list options accept.
+	Sort string
+}
+
+// Bind parses the filter[...] and sort query parameters of c.
+func (s *Spec) Bind(c echo.Context) (*Query, error) {
+	return s.Parse(c.QueryParams())
+}
+
+// Parse parses filter[field]=value, filter[field][op]=value and
+// sort=-field,other. Invalid parameters are reported together as field
+// errors by validation.NewValidationError.
+func (s *Spec) Parse(params url.Values) (*Query, error) {
+	query := &Query{}
+	var problems validation.CustomValidationErrors
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+
+		matches := filterParamRegex.FindStringSubmatch(key)
+		if matches == nil {
+			problems = append(problems, validation.CustomValidationError{Field: key, Message: "is not a valid filter", Key: "filter.invalid"})
+			continue
+		}
+
+		name, op := matches[1], Operator(matches[2])
+		if op == "" {
+			op = Eq
+		}
+
+		field, ok := s.Fields[name]
+		if !ok {
+			problems = append(problems, validation.CustomValidationError{Field: key, Message: "is not a filterable field", Key: "filter.unknown_field"})
+			continue
+		}
+		if !field.allows(op) {
+			problems = append(problems, validation.CustomValidationError{
+				Field: key, Message: fmt.Sprintf("does not support the %s operator", op),
+				Key: "filter.unsupported_operator", Args: []any{op},
+			})
+			continue
+		}
+
+		for _, raw := range params[key] {
+			condition, err := field.condition(name, op, raw)
+			if err != nil {
+				problems = append(problems, validation.CustomValidationError{Field: key, Message: err.message, Key: err.key, Args: err.args})
+				continue
+			}
+			query.Conditions = append(query.Conditions, condition)
+		}
+	}
+
+	if sort := params.Get("sort"); sort != "" {
+		var columns []string
+		for _, name := range strings.Split(sort, ",") {
+			name = strings.TrimSpace(name)
+			trimmed, desc := strings.CutPrefix(name, "-")
+
+			column, ok := s.Sortable[trimmed]
+			if !ok {
+				problems = append(problems, validation.CustomValidationError{
+					Field: "sort", Message: fmt.Sprintf("cannot sort by %q", trimmed),
+					Key: "filter.invalid_sort", Args: []any{trimmed},
+				})
+				continue
+			}
+			if desc {
+				column = "-" + column
+			}
+			columns = append(columns, column)
+		}
+		query.Sort = strings.Join(columns, ",")
+	}
+
+	if len(problems) > 0 {
+		return nil, validation.NewValidationError(problems)
+	}
+	return query, nil
+}
+
+func (f Field) allows(op Operator) bool {
+	if len(f.Operators) == 0 {
+		return op == Eq || op == In
+	}
+	return slices.Contains(f.Operators, op)
+}
+
+// valueError describes an invalid filter value, with the key that
+// translates the message.
+type valueError struct {
+	message string
+	key     string
+	args    []any
+}
+
+func (f Field) condition(name string, op Operator, raw string) (Condition, *valueError) {
+	column := f.Column
+	if column == "" {
+		column = name
+	}
+
+	rawValues := []string{raw}
+	switch op {
+	case In:
+		rawValues = strings.Split(raw, ",")
+		if len(rawValues) > MaxInValues {
+			return Condition{}, &valueError{
+				message: fmt.Sprintf("must not list more than %d values", MaxInValues),
+				key:     "filter.too_many_values", args: []any{MaxInValues},
+			}
+		}
+	case Like:
+		if f.Type != String {
+			return Condition{}, &valueError{message: "like only applies to text fields", key: "filter.like_requires_text"}
+		}
+	}
+
+	values := make([]any, 0, len(rawValues))
+	for _, rawValue := range rawValues {
+		value, err := f.Type.parse(strings.TrimSpace(rawValue))
+		if err != nil {
+			return Condition{}, err
+		}
+		values = append(values, value)
+	}
+
+	return Condition{Column: column, Operator: op, Values: values}, nil
+}
+
+func (t Type) parse(raw string) (any, *valueError) {
+	switch t {
+	case Int:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, &valueError{message: "must be an integer", key: "filter.must_be_integer"}
+		}
+		return value, nil
+	case Float:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, &valueError{message: "must be a number", key: "filter.must_be_number"}
+		}
+		return value, nil
+	case Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, &valueError{message: "must be true or false", key: "filter.must_be_boolean"}
+		}
+		return value, nil
+	case Time:
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, &valueError{message: "must be an RFC 3339 timestamp", key: "filter.must_be_timestamp"}
+		}
+		return value, nil
+	case UUID:
+		value, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, &valueError{message: "must be a valid UUID", key: "filter.must_be_uuid"}
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
+
+// Where renders the conditions joined by AND, numbering placeholders from
+// firstArg. It returns an empty string when there are no conditions.
+func (q *Query) Where(firstArg int) (string, []any) {
+	if q == nil || len(q.Conditions) == 0 {
+		return "", nil
+	}
+
+	var (
+		clauses []string
+		args    []any
+	)
+	next := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(firstArg+len(args)-1)
+	}
+
+	for _, condition := range q.Conditions {
+		column := pgx.Identifier{condition.Column}.Sanitize()
+
+		switch condition.Operator {
+		case In:
+			placeholders := make([]string, 0, len(condition.Values))
+			for _, value := range condition.Values {
+				placeholders = append(placeholders, next(value))
+			}
+			clauses = append(clauses, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")))
+		case Like:
+			// match a substring; wildcards in the value are taken literally
+			pattern := "%" + likeEscaper.Replace(condition.Values[0].(string)) + "%"
+			clauses = append(clauses, fmt.Sprintf("%s ILIKE %s", column, next(pattern)))
+		default:
+			clauses = append(clauses, fmt.Sprintf("%s %s %s", column, operatorSQL[condition.Operator], next(condition.Values[0])))
+		}
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
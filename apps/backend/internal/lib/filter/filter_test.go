@@ -0,0 +1,269 @@
+package filter_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/errs"
+	"github.com/C0deNe0/go-boiler/internal/lib/filter"
+	"github.com/google/uuid"
+)
+
+var spec = &filter.Spec{
+	Fields: map[string]filter.Field{
+		"status":   {},
+		"title":    {Operators: []filter.Operator{filter.Eq, filter.Like}},
+		"priority": {Type: filter.Int, Operators: []filter.Operator{filter.Eq, filter.Gt, filter.Lte, filter.In}},
+		"due":      {Column: "due_date", Type: filter.Time, Operators: []filter.Operator{filter.Gte, filter.Lt}},
+		"owner":    {Column: "owner_id", Type: filter.UUID},
+		"done":     {Column: "completed", Type: filter.Bool},
+	},
+	Sortable: map[string]string{
+		"created": "created_at",
+		"title":   "title",
+	},
+}
+
+func TestParse(t *testing.T) {
+	due := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	owner := uuid.MustParse("0b4a7c1e-3f7e-4a53-9d3b-5d0a0c6f6a11")
+
+	tests := []struct {
+		name           string
+		query          string
+		wantConditions []filter.Condition
+		wantSort       string
+	}{
+		{
+			name: "no filters",
+		},
+		{
+			name:           "default operator is eq",
+			query:          "filter[status]=open",
+			wantConditions: []filter.Condition{{Column: "status", Operator: filter.Eq, Values: []any{"open"}}},
+		},
+		{
+			name:           "declared column",
+			query:          "filter[due][gte]=2024-05-01T00:00:00Z",
+			wantConditions: []filter.Condition{{Column: "due_date", Operator: filter.Gte, Values: []any{due}}},
+		},
+		{
+			name:  "typed values",
+			query: "filter[owner]=" + owner.String() + "&filter[done]=true&filter[priority][gt]=2",
+			wantConditions: []filter.Condition{
+				{Column: "completed", Operator: filter.Eq, Values: []any{true}},
+				{Column: "owner_id", Operator: filter.Eq, Values: []any{owner}},
+				{Column: "priority", Operator: filter.Gt, Values: []any{int64(2)}},
+			},
+		},
+		{
+			name:           "in splits values",
+			query:          "filter[priority][in]=1,%202,3",
+			wantConditions: []filter.Condition{{Column: "priority", Operator: filter.In, Values: []any{int64(1), int64(2), int64(3)}}},
+		},
+		{
+			name:  "repeated parameter",
+			query: "filter[status]=open&filter[status]=closed",
+			wantConditions: []filter.Condition{
+				{Column: "status", Operator: filter.Eq, Values: []any{"open"}},
+				{Column: "status", Operator: filter.Eq, Values: []any{"closed"}},
+			},
+		},
+		{
+			name:     "sort maps to columns",
+			query:    "sort=-created,%20title",
+			wantSort: "-created_at,title",
+		},
+		{
+			name:           "other parameters are ignored",
+			query:          "page=2&limit=10&filter[status]=open",
+			wantConditions: []filter.Condition{{Column: "status", Operator: filter.Eq, Values: []any{"open"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			query, err := spec.Parse(params)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(query.Conditions, tt.wantConditions) {
+				t.Errorf("Conditions = %#v, want %#v", query.Conditions, tt.wantConditions)
+			}
+			if query.Sort != tt.wantSort {
+				t.Errorf("Sort = %q, want %q", query.Sort, tt.wantSort)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantFields []string
+	}{
+		{name: "undeclared field", query: "filter[password]=x", wantFields: []string{"filter[password] filter.unknown_field"}},
+		{name: "column name instead of field", query: "filter[due_date][gte]=2024-05-01T00:00:00Z", wantFields: []string{"filter[due_date][gte] filter.unknown_field"}},
+		{name: "sql in field name", query: "filter[status%3Bdrop%20table%20users]=x", wantFields: []string{"filter[status;drop table users] filter.invalid"}},
+		{name: "quoted field name", query: `filter["status"]=x`, wantFields: []string{`filter["status"] filter.invalid`}},
+		{name: "nested brackets", query: "filter[status][eq][x]=x", wantFields: []string{"filter[status][eq][x] filter.invalid"}},
+		{name: "operator not allowed", query: "filter[status][gt]=a", wantFields: []string{"filter[status][gt] filter.unsupported_operator"}},
+		{name: "unknown operator", query: "filter[priority][between]=1", wantFields: []string{"filter[priority][between] filter.unsupported_operator"}},
+		{name: "like on non-text field", query: "filter[priority][like]=1", wantFields: []string{"filter[priority][like] filter.unsupported_operator"}},
+		{name: "invalid integer", query: "filter[priority]=high", wantFields: []string{"filter[priority] filter.must_be_integer"}},
+		{name: "invalid value in list", query: "filter[priority][in]=1,two", wantFields: []string{"filter[priority][in] filter.must_be_integer"}},
+		{name: "invalid uuid", query: "filter[owner]=1", wantFields: []string{"filter[owner] filter.must_be_uuid"}},
+		{name: "invalid boolean", query: "filter[done]=maybe", wantFields: []string{"filter[done] filter.must_be_boolean"}},
+		{name: "invalid time", query: "filter[due][lt]=yesterday", wantFields: []string{"filter[due][lt] filter.must_be_timestamp"}},
+		{name: "unsortable field", query: "sort=-password", wantFields: []string{"sort filter.invalid_sort"}},
+		{name: "sort by column name", query: "sort=created_at", wantFields: []string{"sort filter.invalid_sort"}},
+		{
+			name:       "every problem is reported",
+			query:      "filter[nope]=1&filter[priority]=x&sort=nope",
+			wantFields: []string{"filter[nope] filter.unknown_field", "filter[priority] filter.must_be_integer", "sort filter.invalid_sort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			query, err := spec.Parse(params)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %#v, want an error", tt.query, query)
+			}
+
+			var httpErr *errs.HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("Parse(%q) returned %T, want *errs.HTTPError", tt.query, err)
+			}
+			var fields []string
+			for _, fieldError := range httpErr.Errors {
+				fields = append(fields, fieldError.Field+" "+fieldError.Key)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("field errors for %q, want %q", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestParseLimitsInValues(t *testing.T) {
+	values := make([]string, filter.MaxInValues+1)
+	for i := range values {
+		values[i] = "1"
+	}
+	params := url.Values{"filter[priority][in]": {strings.Join(values, ",")}}
+
+	_, err := spec.Parse(params)
+	var httpErr *errs.HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Parse accepted %d in values", len(values))
+	}
+	if len(httpErr.Errors) != 1 || httpErr.Errors[0].Key != "filter.too_many_values" {
+		t.Errorf("field errors = %+v, want filter.too_many_values", httpErr.Errors)
+	}
+
+	params = url.Values{"filter[priority][in]": {strings.Join(values[1:], ",")}}
+	if _, err := spec.Parse(params); err != nil {
+		t.Fatalf("Parse rejected %d in values: %v", len(values)-1, err)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    *filter.Query
+		firstArg int
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "nil query",
+			firstArg: 1,
+		},
+		{
+			name:     "no conditions",
+			query:    &filter.Query{},
+			firstArg: 1,
+		},
+		{
+			name: "comparison operators",
+			query: &filter.Query{Conditions: []filter.Condition{
+				{Column: "status", Operator: filter.Eq, Values: []any{"open"}},
+				{Column: "status", Operator: filter.Ne, Values: []any{"closed"}},
+				{Column: "priority", Operator: filter.Gt, Values: []any{int64(1)}},
+				{Column: "priority", Operator: filter.Gte, Values: []any{int64(2)}},
+				{Column: "priority", Operator: filter.Lt, Values: []any{int64(9)}},
+				{Column: "priority", Operator: filter.Lte, Values: []any{int64(8)}},
+			}},
+			firstArg: 1,
+			wantSQL: `"status" = $1 AND "status" <> $2 AND "priority" > $3 AND ` +
+				`"priority" >= $4 AND "priority" < $5 AND "priority" <= $6`,
+			wantArgs: []any{"open", "closed", int64(1), int64(2), int64(9), int64(8)},
+		},
+		{
+			name: "placeholders continue from firstArg",
+			query: &filter.Query{Conditions: []filter.Condition{
+				{Column: "status", Operator: filter.Eq, Values: []any{"open"}},
+				{Column: "priority", Operator: filter.In, Values: []any{int64(1), int64(2)}},
+			}},
+			firstArg: 3,
+			wantSQL:  `"status" = $3 AND "priority" IN ($4, $5)`,
+			wantArgs: []any{"open", int64(1), int64(2)},
+		},
+		{
+			name: "like escapes wildcards",
+			query: &filter.Query{Conditions: []filter.Condition{
+				{Column: "title", Operator: filter.Like, Values: []any{`50%_off\`}},
+			}},
+			firstArg: 1,
+			wantSQL:  `"title" ILIKE $1`,
+			wantArgs: []any{`%50\%\_off\\%`},
+		},
+		{
+			name: "values are never inlined",
+			query: &filter.Query{Conditions: []filter.Condition{
+				{Column: "status", Operator: filter.Eq, Values: []any{"x'; drop table users; --"}},
+			}},
+			firstArg: 1,
+			wantSQL:  `"status" = $1`,
+			wantArgs: []any{"x'; drop table users; --"},
+		},
+		{
+			name: "columns are quoted",
+			query: &filter.Query{Conditions: []filter.Condition{
+				{Column: `weird"column`, Operator: filter.Eq, Values: []any{"x"}},
+			}},
+			firstArg: 1,
+			wantSQL:  `"weird""column" = $1`,
+			wantArgs: []any{"x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.query.Where(tt.firstArg)
+			if sql != tt.wantSQL {
+				t.Errorf("Where() sql = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Where() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
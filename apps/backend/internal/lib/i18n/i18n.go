@@ -0,0 +1,143 @@
+// Package i18n translates user facing messages. English source strings live
+// next to the code that produces them; the embedded locale files hold
+// translations by message key. A message is looked up in the requested
+// locale, then its parent locales, and falls back to the English source.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// DefaultLocale is the language of the source strings.
+var DefaultLocale = language.English
+
+var (
+	// translations maps a locale to its messages by key
+	translations = mustLoad(localeFiles)
+	supported    = supportedLocales(translations)
+	matcher      = language.NewMatcher(supported)
+)
+
+func mustLoad(fsys fs.FS) map[language.Tag]map[string]string {
+	files, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		panic(err)
+	}
+
+	loaded := make(map[language.Tag]map[string]string, len(files))
+	for _, file := range files {
+		tag := language.MustParse(strings.TrimSuffix(path.Base(file), ".json"))
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid locale file %s: %v", file, err))
+		}
+		loaded[tag] = messages
+	}
+	return loaded
+}
+
+// supportedLocales lists DefaultLocale first, as the matcher falls back to
+// the first tag.
+func supportedLocales(loaded map[language.Tag]map[string]string) []language.Tag {
+	tags := []language.Tag{DefaultLocale}
+	for tag := range loaded {
+		if tag != DefaultLocale {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Supported returns the locales messages can be rendered in.
+func Supported() []language.Tag {
+	return append([]language.Tag(nil), supported...)
+}
+
+// Match resolves preferences such as an Accept-Language header value or a
+// stored user preference to a supported locale, DefaultLocale when none fits.
+func Match(preferences ...string) language.Tag {
+	if locale, ok := match(preferences...); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+func match(preferences ...string) (language.Tag, bool) {
+	var tags []language.Tag
+	for _, preference := range preferences {
+		parsed, _, err := language.ParseAcceptLanguage(preference)
+		if err == nil {
+			tags = append(tags, parsed...)
+		}
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	return supported[index], confidence != language.No
+}
+
+// Translate renders the message key in locale with fmt-style args, trying
+// parent locales before returning fallback, the English source string.
+func Translate(locale language.Tag, key, fallback string, args ...any) string {
+	if key == "" {
+		return fallback
+	}
+
+	for tag := locale; ; tag = tag.Parent() {
+		if message, ok := translations[tag][key]; ok {
+			return fmt.Sprintf(message, args...)
+		}
+		if tag == language.Und {
+			return fallback
+		}
+	}
+}
+
+type localeContextKey struct{}
+type preferenceContextKey struct{}
+
+// WithLocale stores the locale negotiated for the request, usually from
+// its Accept-Language header.
+func WithLocale(ctx context.Context, locale language.Tag) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// WithPreference stores the locale the user chose, which takes precedence
+// over the negotiated locale.
+func WithPreference(ctx context.Context, preference string) context.Context {
+	return context.WithValue(ctx, preferenceContextKey{}, preference)
+}
+
+// FromContext returns the user's preferred locale when it is supported,
+// otherwise the negotiated locale or DefaultLocale.
+func FromContext(ctx context.Context) language.Tag {
+	if preference, ok := ctx.Value(preferenceContextKey{}).(string); ok {
+		if locale, ok := match(preference); ok {
+			return locale
+		}
+	}
+	if locale, ok := ctx.Value(localeContextKey{}).(language.Tag); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// T translates key into the locale of ctx; see Translate.
+func T(ctx context.Context, key, fallback string, args ...any) string {
+	return Translate(FromContext(ctx), key, fallback, args...)
+}
@@ -0,0 +1,136 @@
+package i18n_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/C0deNe0/go-boiler/internal/lib/i18n"
+	"golang.org/x/text/language"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences []string
+		want        language.Tag
+	}{
+		{name: "no preference", want: language.English},
+		{name: "empty header", preferences: []string{""}, want: language.English},
+		{name: "invalid header", preferences: []string{"not a locale!"}, want: language.English},
+		{name: "exact match", preferences: []string{"es"}, want: language.Spanish},
+		{name: "regional variant", preferences: []string{"es-MX"}, want: language.Spanish},
+		{name: "unsupported language", preferences: []string{"fr-FR"}, want: language.English},
+		{name: "unsupported before supported", preferences: []string{"fr-CH, fr;q=0.9, es;q=0.5"}, want: language.Spanish},
+		{name: "highest q-value wins", preferences: []string{"en;q=0.5, es;q=0.9"}, want: language.Spanish},
+		{name: "header order without q-values", preferences: []string{"en-GB, es"}, want: language.English},
+		{name: "wildcard", preferences: []string{"*"}, want: language.English},
+		{name: "first preference wins", preferences: []string{"es", "en"}, want: language.Spanish},
+		{name: "invalid preference skipped", preferences: []string{"!!", "es"}, want: language.Spanish},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := i18n.Match(tt.preferences...); got != tt.want {
+				t.Errorf("Match(%q) = %s, want %s", tt.preferences, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name     string
+		locale   language.Tag
+		key      string
+		fallback string
+		args     []any
+		want     string
+	}{
+		{
+			name:     "translated",
+			locale:   language.Spanish,
+			key:      "validation.required",
+			fallback: "is required",
+			want:     "es obligatorio",
+		},
+		{
+			name:     "parent locale",
+			locale:   language.MustParse("es-MX"),
+			key:      "validation.required",
+			fallback: "is required",
+			want:     "es obligatorio",
+		},
+		{
+			name:     "args",
+			locale:   language.Spanish,
+			key:      "validation.min_length",
+			fallback: "must be at least 3 characters",
+			args:     []any{"3"},
+			want:     "debe tener al menos 3 caracteres",
+		},
+		{
+			name:     "missing key",
+			locale:   language.Spanish,
+			key:      "test.missing",
+			fallback: "source string",
+			want:     "source string",
+		},
+		{
+			name:     "empty key",
+			locale:   language.Spanish,
+			fallback: "source string",
+			want:     "source string",
+		},
+		{
+			name:     "default locale",
+			locale:   language.English,
+			key:      "validation.required",
+			fallback: "is required",
+			want:     "is required",
+		},
+		{
+			name:     "unsupported locale",
+			locale:   language.French,
+			key:      "validation.required",
+			fallback: "is required",
+			want:     "is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := i18n.Translate(tt.locale, tt.key, tt.fallback, tt.args...); got != tt.want {
+				t.Errorf("Translate(%s, %q) = %q, want %q", tt.locale, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want language.Tag
+	}{
+		{name: "nothing set", ctx: context.Background(), want: language.English},
+		{name: "negotiated", ctx: i18n.WithLocale(context.Background(), language.Spanish), want: language.Spanish},
+		{
+			name: "preference over negotiated",
+			ctx:  i18n.WithPreference(i18n.WithLocale(context.Background(), language.English), "es-AR"),
+			want: language.Spanish,
+		},
+		{
+			name: "unsupported preference",
+			ctx:  i18n.WithPreference(i18n.WithLocale(context.Background(), language.Spanish), "fr"),
+			want: language.Spanish,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := i18n.FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+// Package identity carries the authenticated user in request contexts, so
+// code below the HTTP layer, such as repositories, can tell who acts.
+package identity
+
+import "context"
+
+type userIDContextKey struct{}
+
+// WithUserID stores the ID of the authenticated user in ctx. RequireAuth sets
+// it for every authenticated request.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDContextKey{}, userID)
+}
+
+// UserID returns the user ID stored by WithUserID, or an empty string when
+// the context is not authenticated.
+func UserID(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDContextKey{}).(string)
+	return userID
+}
@@ -14,12 +14,15 @@ const (
 type WelcomeEmailPayload struct {
 	To        string `json:"to"`
 	FirstName string `json:"first_name"`
+	// Locale is the recipient's language, e.g. i18n.FromContext(ctx).String()
+	Locale string `json:"locale,omitempty"`
 }
 
-func NewWelcomeEmailTask(to, firstname string) (*asynq.Task, error) {
+func NewWelcomeEmailTask(to, firstname, locale string) (*asynq.Task, error) {
 	payload, err := json.Marshal(WelcomeEmailPayload{
 		To:        to,
 		FirstName: firstname,
+		Locale:    locale,
 	})
 
 	if err != nil {
@@ -30,6 +30,7 @@ func (j *JobService) handleWelcomeEmailTask(ctx context.Context, t *asynq.Task)
 	err := emailClient.SendWelcomeEmail(
 		p.To,
 		p.FirstName,
+		p.Locale,
 	)
 
 	if err != nil {
@@ -0,0 +1,253 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/database"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/rs/zerolog"
+)
+
+const (
+	DefaultOutboxQueue    = "default"
+	DefaultOutboxMaxRetry = 3
+	DefaultOutboxTimeout  = 30 * time.Second
+
+	outboxPollInterval    = time.Second
+	outboxMetricsInterval = 30 * time.Second
+	outboxCleanupInterval = time.Hour
+	outboxBatchSize       = 100
+	// outboxRetention is how long published rows are kept, so that their
+	// dedup keys keep rejecting repeats
+	outboxRetention = 7 * 24 * time.Hour
+	// outboxMaxBackoffSeconds caps the delay before a failed publish is retried
+	outboxMaxBackoffSeconds = 300
+)
+
+// OutboxOptions are the asynq options a task is published with.
+type OutboxOptions struct {
+	// Queue defaults to DefaultOutboxQueue.
+	Queue string
+	// MaxRetry defaults to DefaultOutboxMaxRetry.
+	MaxRetry int
+	// Timeout defaults to DefaultOutboxTimeout.
+	Timeout time.Duration
+	// DedupKey makes Enqueue a no-op when a task with the same key was
+	// already written, and is used as the asynq task ID.
+	DedupKey string
+}
+
+// Outbox stores tasks in the job_outbox table so that they are enqueued if
+// and only if the surrounding transaction commits. A relay publishes pending
+// rows to asynq at least once; asynq task IDs drop repeated publishes.
+type Outbox struct {
+	db     *database.Database
+	client *asynq.Client
+	logger *zerolog.Logger
+	app    *newrelic.Application
+
+	stop context.CancelFunc
+	done sync.WaitGroup
+}
+
+// NewOutbox creates an outbox. client publishes for the relay and may be nil
+// in processes that only enqueue and never call StartRelay.
+func NewOutbox(db *database.Database, client *asynq.Client, logger *zerolog.Logger, app *newrelic.Application) *Outbox {
+	return &Outbox{
+		db:     db,
+		client: client,
+		logger: logger,
+		app:    app,
+	}
+}
+
+// Enqueue writes task to the outbox through the transaction carried by ctx
+// (see database.WithTx), or on its own when there is none.
+func (o *Outbox) Enqueue(ctx context.Context, task *asynq.Task, opts OutboxOptions) error {
+	if opts.Queue == "" {
+		opts.Queue = DefaultOutboxQueue
+	}
+	if opts.MaxRetry == 0 {
+		opts.MaxRetry = DefaultOutboxMaxRetry
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultOutboxTimeout
+	}
+
+	var dedupKey *string
+	if opts.DedupKey != "" {
+		dedupKey = &opts.DedupKey
+	}
+
+	_, err := o.db.Querier(ctx).Exec(ctx, `
+INSERT INTO job_outbox (task_type, payload, queue, max_retry, timeout_seconds, dedup_key)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (dedup_key) WHERE dedup_key IS NOT NULL DO NOTHING`,
+		task.Type(), task.Payload(), opts.Queue, opts.MaxRetry, int(opts.Timeout.Seconds()), dedupKey)
+	if err != nil {
+		return fmt.Errorf("failed to write %s task to outbox: %w", task.Type(), err)
+	}
+	return nil
+}
+
+type outboxEntry struct {
+	ID             int64   `db:"id"`
+	TaskType       string  `db:"task_type"`
+	Payload        []byte  `db:"payload"`
+	Queue          string  `db:"queue"`
+	MaxRetry       int     `db:"max_retry"`
+	TimeoutSeconds int     `db:"timeout_seconds"`
+	DedupKey       *string `db:"dedup_key"`
+}
+
+func (e outboxEntry) taskID() string {
+	if e.DedupKey != nil {
+		return *e.DedupKey
+	}
+	return "outbox:" + strconv.FormatInt(e.ID, 10)
+}
+
+// StartRelay publishes pending rows until Stop is called. Relays on several
+// workers share the work through row locks.
+func (o *Outbox) StartRelay() {
+	ctx, cancel := context.WithCancel(context.Background())
+	o.stop = cancel
+
+	o.done.Add(1)
+	go func() {
+		defer o.done.Done()
+
+		poll := time.NewTicker(outboxPollInterval)
+		defer poll.Stop()
+		metrics := time.NewTicker(outboxMetricsInterval)
+		defer metrics.Stop()
+		cleanup := time.NewTicker(outboxCleanupInterval)
+		defer cleanup.Stop()
+
+		o.logger.Info().Msg("starting job outbox relay")
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-poll.C:
+				// drain the backlog before waiting for the next tick
+				for {
+					published, err := o.relayBatch(ctx)
+					if err != nil && ctx.Err() == nil {
+						o.logger.Error().Err(err).Msg("job outbox relay failed")
+					}
+					if err != nil || published < outboxBatchSize {
+						break
+					}
+				}
+			case <-metrics.C:
+				o.recordLag(ctx)
+			case <-cleanup.C:
+				o.cleanup(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends the relay and waits for the batch in flight.
+func (o *Outbox) Stop() {
+	if o.stop == nil {
+		return
+	}
+
+	o.logger.Info().Msg("stopping job outbox relay")
+	o.stop()
+	o.done.Wait()
+}
+
+func (o *Outbox) relayBatch(ctx context.Context) (int, error) {
+	processed := 0
+
+	// publishing is a side effect, so the transaction must not be retried
+	err := o.db.WithTx(ctx, database.TxOptions{MaxRetries: -1}, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+SELECT id, task_type, payload, queue, max_retry, timeout_seconds, dedup_key
+FROM job_outbox
+WHERE published_at IS NULL AND available_at <= NOW()
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED`, outboxBatchSize)
+		if err != nil {
+			return err
+		}
+		entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[outboxEntry])
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			_, err := o.client.EnqueueContext(ctx, asynq.NewTask(entry.TaskType, entry.Payload),
+				asynq.TaskID(entry.taskID()),
+				asynq.Queue(entry.Queue),
+				asynq.MaxRetry(entry.MaxRetry),
+				asynq.Timeout(time.Duration(entry.TimeoutSeconds)*time.Second))
+
+			// a conflicting task ID means an earlier relay already published it
+			if err == nil || errors.Is(err, asynq.ErrTaskIDConflict) {
+				_, err = tx.Exec(ctx, `UPDATE job_outbox SET published_at = NOW(), last_error = NULL WHERE id = $1`, entry.ID)
+			} else {
+				o.logger.Warn().Err(err).Int64("outbox_id", entry.ID).Str("type", entry.TaskType).Msg("failed to publish outbox task")
+				_, err = tx.Exec(ctx, `
+UPDATE job_outbox
+SET attempts = attempts + 1,
+    last_error = $2,
+    available_at = NOW() + make_interval(secs => LEAST(POWER(attempts + 1, 2), $3))
+WHERE id = $1`, entry.ID, err.Error(), outboxMaxBackoffSeconds)
+			}
+			if err != nil {
+				return err
+			}
+			processed++
+		}
+		return nil
+	})
+
+	return processed, err
+}
+
+// recordLag publishes how many rows are pending and how long the oldest has waited.
+func (o *Outbox) recordLag(ctx context.Context) {
+	var (
+		pending    int64
+		lagSeconds float64
+	)
+	err := o.db.Pool.QueryRow(ctx, `
+SELECT COUNT(*), COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(created_at)), 0)::float8
+FROM job_outbox
+WHERE published_at IS NULL`).Scan(&pending, &lagSeconds)
+	if err != nil {
+		o.logger.Error().Err(err).Msg("failed to measure job outbox lag")
+		return
+	}
+
+	o.logger.Debug().Int64("pending", pending).Float64("lag_seconds", lagSeconds).Msg("job outbox lag")
+
+	if o.app != nil {
+		o.app.RecordCustomMetric("Custom/JobOutbox/Pending", float64(pending))
+		o.app.RecordCustomMetric("Custom/JobOutbox/LagSeconds", lagSeconds)
+	}
+}
+
+func (o *Outbox) cleanup(ctx context.Context) {
+	tag, err := o.db.Pool.Exec(ctx,
+		`DELETE FROM job_outbox WHERE published_at < NOW() - make_interval(secs => $1)`,
+		outboxRetention.Seconds())
+	if err != nil {
+		o.logger.Error().Err(err).Msg("failed to clean up job outbox")
+		return
+	}
+
+	o.logger.Debug().Int64("deleted", tag.RowsAffected()).Msg("cleaned up published outbox tasks")
+}
@@ -0,0 +1,33 @@
+package logger
+
+import "context"
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	userIDContextKey
+)
+
+// WithRequestID stores the request ID in ctx for code that has no echo.Context,
+// such as database tracers.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// WithUserID stores the authenticated user ID in ctx.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
+// UserIDFromContext returns the user ID stored by WithUserID.
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDContextKey).(string)
+	return userID
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/C0deNe0/go-boiler/internal/config"
@@ -18,11 +19,25 @@ import (
 // LoggerService manages New Relic integration and logger creation
 type LoggerService struct {
 	nrApp *newrelic.Application
+	level *dynamicLevel
+}
+
+// dynamicLevel is a zerolog.Sampler that drops events below a level
+// which can be changed while the process is running
+type dynamicLevel struct {
+	level atomic.Int32
+}
+
+func (d *dynamicLevel) Sample(lvl zerolog.Level) bool {
+	return lvl >= zerolog.Level(d.level.Load())
 }
 
 // NewLoggerService creates a new logger service with New Relic integration
 func NewLoggerService(cfg *config.ObserveabilityConfig) *LoggerService {
-	service := &LoggerService{}
+	service := &LoggerService{
+		level: &dynamicLevel{},
+	}
+	service.SetLevel(cfg.GetLogLevel())
 
 	if cfg.NewRelic.LicenseKey == "" {
 		fmt.Println("New Relic license key not provided, skipping initialization")
@@ -32,7 +47,7 @@ func NewLoggerService(cfg *config.ObserveabilityConfig) *LoggerService {
 	var configOptions []newrelic.ConfigOption
 	configOptions = append(configOptions,
 		newrelic.ConfigAppName(cfg.ServiceName),
-		newrelic.ConfigLicense(cfg.NewRelic.LicenseKey),
+		newrelic.ConfigLicense(cfg.NewRelic.LicenseKey.Value()),
 		newrelic.ConfigAppLogForwardingEnabled(cfg.NewRelic.AppLogForwardEnabled),
 		newrelic.ConfigDistributedTracerEnabled(cfg.NewRelic.DistributedTracingEnabled),
 	)
@@ -65,6 +80,32 @@ func (ls *LoggerService) GetApplication() *newrelic.Application {
 	return ls.nrApp
 }
 
+// SetLevel changes the level of every logger created with this service
+func (ls *LoggerService) SetLevel(level string) {
+	ls.level.level.Store(int32(ParseLevel(level)))
+}
+
+// Level returns the current level of loggers created with this service
+func (ls *LoggerService) Level() zerolog.Level {
+	return zerolog.Level(ls.level.level.Load())
+}
+
+// ParseLevel converts a configured level name to a zerolog level, defaulting to info
+func ParseLevel(level string) zerolog.Level {
+	switch level {
+	case "debug":
+		return zerolog.DebugLevel
+	case "info":
+		return zerolog.InfoLevel
+	case "warn":
+		return zerolog.WarnLevel
+	case "error":
+		return zerolog.ErrorLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
+
 // NewLogger creates a new logger with specified level (backward compatibility)
 func NewLogger(level string, isProd bool) zerolog.Logger {
 	return NewLoggerWithService(&config.ObserveabilityConfig{
@@ -87,21 +128,7 @@ func NewLoggerWithConfig(cfg *config.ObserveabilityConfig) zerolog.Logger {
 
 // NewLoggerWithService creates a logger with full config and logger service
 func NewLoggerWithService(cfg *config.ObserveabilityConfig, loggerService *LoggerService) zerolog.Logger {
-	var logLevel zerolog.Level
-	level := cfg.GetLogLevel()
-
-	switch level {
-	case "debug":
-		logLevel = zerolog.DebugLevel
-	case "info":
-		logLevel = zerolog.InfoLevel
-	case "warn":
-		logLevel = zerolog.WarnLevel
-	case "error":
-		logLevel = zerolog.ErrorLevel
-	default:
-		logLevel = zerolog.InfoLevel
-	}
+	logLevel := ParseLevel(cfg.GetLogLevel())
 
 	// Don't set global level - let each logger have its own level
 	zerolog.TimeFieldFormat = "2006-01-02 15:04:05"
@@ -138,6 +165,12 @@ func NewLoggerWithService(cfg *config.ObserveabilityConfig, loggerService *Logge
 		Str("environment", cfg.Environment).
 		Logger()
 
+	// Let the logger service filter levels so they can change at runtime
+	if loggerService != nil {
+		loggerService.SetLevel(cfg.GetLogLevel())
+		logger = logger.Level(zerolog.TraceLevel).Sample(loggerService.level)
+	}
+
 	// Include stack traces for errors in development
 	if !cfg.IsProduction() {
 		logger = logger.With().Stack().Logger()
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/C0deNe0/go-boiler/internal/errs"
+	"github.com/C0deNe0/go-boiler/internal/lib/identity"
+	"github.com/C0deNe0/go-boiler/internal/logger"
 	"github.com/C0deNe0/go-boiler/internal/server"
 	"github.com/clerk/clerk-sdk-go/v2"
 	clerkhttp "github.com/clerk/clerk-sdk-go/v2/http"
@@ -56,6 +58,8 @@ func (auth *AuthMiddleware) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc
 		c.Set("user_id", claims.Subject)
 		c.Set("user_role", claims.ActiveOrganizationRole)
 		c.Set("permission", claims.Claims.ActiveOrganizationPermissions)
+		ctx := identity.WithUserID(c.Request().Context(), claims.Subject)
+		c.SetRequest(c.Request().WithContext(logger.WithUserID(ctx, claims.Subject)))
 
 		auth.server.Logger.Info().Str("function", "RequireAuth").Str("user_id", claims.Subject).Str("request_id", GetRequestID(c)).Dur("duration", time.Since(start)).Msg("User authenticated successfully")
 		return next(c)
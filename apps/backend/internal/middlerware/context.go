@@ -3,6 +3,8 @@ package middlerware
 import (
 	"context"
 
+	"github.com/C0deNe0/go-boiler/internal/database"
+	"github.com/C0deNe0/go-boiler/internal/lib/i18n"
 	"github.com/C0deNe0/go-boiler/internal/logger"
 	"github.com/C0deNe0/go-boiler/internal/server"
 	"github.com/labstack/echo/v4"
@@ -17,6 +19,8 @@ const (
 	LoggerKey   = "logger"
 )
 
+const headerAcceptLanguage = "Accept-Language"
+
 type ContextEnhancer struct {
 	server *server.Server
 }
@@ -54,6 +58,9 @@ func (ce *ContextEnhancer) EnhanceContext() echo.MiddlewareFunc {
 			c.Set(LoggerKey, &contextLogger)
 
 			ctx := context.WithValue(c.Request().Context(), LoggerKey, &contextLogger)
+			ctx = database.WithSession(ctx)
+			ctx = logger.WithRequestID(ctx, requestID)
+			ctx = i18n.WithLocale(ctx, i18n.Match(c.Request().Header.Get(headerAcceptLanguage)))
 			c.SetRequest(c.Request().WithContext(ctx))
 
 			return next(c)
@@ -2,9 +2,16 @@ package middlerware
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
+	"github.com/C0deNe0/go-boiler/internal/config"
 	"github.com/C0deNe0/go-boiler/internal/errs"
+	"github.com/C0deNe0/go-boiler/internal/lib/i18n"
 	"github.com/C0deNe0/go-boiler/internal/server"
 	"github.com/C0deNe0/go-boiler/internal/sqlerr"
 	"github.com/labstack/echo/v4"
@@ -12,18 +19,43 @@ import (
 	"github.com/rs/zerolog"
 )
 
+const (
+	headerRetryAfter      = "Retry-After"
+	headerContentLanguage = "Content-Language"
+)
+
 type GlobalMiddlewares struct {
-	server *server.Server
+	server         *server.Server
+	allowedOrigins atomic.Pointer[[]string]
+	serverConfig   atomic.Pointer[config.ServerConfig]
 }
 
 func NewGlobalMiddleware(s *server.Server) *GlobalMiddlewares {
-	return &GlobalMiddlewares{
+	global := &GlobalMiddlewares{
 		server: s,
 	}
+	global.allowedOrigins.Store(&s.Config.Server.CORSAllowedOrigin)
+	global.serverConfig.Store(&s.Config.Server)
+
+	if s.ConfigWatcher != nil {
+		s.ConfigWatcher.Subscribe(func(_, current *config.Config) {
+			global.allowedOrigins.Store(&current.Server.CORSAllowedOrigin)
+			global.serverConfig.Store(&current.Server)
+		})
+	}
+
+	return global
 }
+
 func (global *GlobalMiddlewares) CORS() echo.MiddlewareFunc {
 	return middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: global.server.Config.Server.CORSAllowedOrigin,
+		// origins are looked up per request so config reloads apply immediately
+		AllowOriginFunc: func(origin string) (bool, error) {
+			origins := *global.allowedOrigins.Load()
+			return slices.Contains(origins, "*") || slices.Contains(origins, origin), nil
+		},
+		// clients read the ETag to send it back in If-Match
+		ExposeHeaders: []string{"ETag"},
 	})
 }
 
@@ -109,7 +141,7 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 		var echoErr *echo.HTTPError
 		if errors.As(err, &echoErr) {
 			if echoErr.Code == http.StatusNotFound {
-				err = errs.NewNotFoundError("Route not found", false, nil)
+				err = errs.NewNotFoundError("Route not found", false, nil).WithMessageKey("error.route_not_found")
 			}
 		} else {
 			// Here we call our sqlerr handler which will convert database errors
@@ -125,6 +157,8 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 	var message string
 	var fieldErrors []errs.FieldError
 	var action *errs.Action
+	var messageKey string
+	var messageArgs []any
 
 	switch {
 	case errors.As(err, &httpErr):
@@ -133,6 +167,8 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 		message = httpErr.Message
 		fieldErrors = httpErr.Errors
 		action = httpErr.Action
+		messageKey = httpErr.MessageKey
+		messageArgs = httpErr.MessageArgs
 
 	case errors.As(err, &echoErr):
 		status = echoErr.Code
@@ -161,13 +197,97 @@ func (global *GlobalMiddlewares) GlobalErrorHandler(err error, c echo.Context) {
 		Msg(message)
 
 	if !c.Response().Committed {
-		_ = c.JSON(status, errs.HTTPError{
-			Code:     code,
-			Message:  message,
-			Status:   status,
-			Override: httpErr != nil && httpErr.Override,
-			Errors:   fieldErrors,
-			Action:   action,
-		})
+		if httpErr != nil && httpErr.RetryAfter > 0 {
+			c.Response().Header().Set(headerRetryAfter,
+				strconv.Itoa(int(math.Ceil(httpErr.RetryAfter.Seconds()))))
+		}
+
+		response := &errs.HTTPError{
+			Code:        code,
+			Message:     message,
+			Status:      status,
+			Override:    httpErr != nil && httpErr.Override,
+			Errors:      fieldErrors,
+			Action:      action,
+			MessageKey:  messageKey,
+			MessageArgs: messageArgs,
+		}
+
+		locale := i18n.FromContext(c.Request().Context())
+		response = response.Localize(locale)
+		c.Response().Header().Set(headerContentLanguage, locale.String())
+
+		serverConfig := global.serverConfig.Load()
+		if !wantsProblem(c, serverConfig.ErrorFormat) {
+			_ = c.JSON(status, response)
+			return
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, errs.MIMEApplicationProblemJSON)
+		_ = c.JSON(status, errs.NewProblem(response, serverConfig.ProblemTypeBaseURL, GetRequestID(c)))
 	}
 }
+
+// wantsProblem reports whether the error is rendered as problem details,
+// either because the server is configured for them or the client prefers
+// them. A negotiated format varies with Accept.
+func wantsProblem(c echo.Context, format string) bool {
+	if format == "problem" {
+		return true
+	}
+
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	return prefersProblem(c.Request().Header.Get(echo.HeaderAccept))
+}
+
+// prefersProblem reports whether accept lists problem details explicitly
+// with a quality above zero and no lower than that of plain JSON. Wildcards
+// keep the JSON default.
+func prefersProblem(accept string) bool {
+	problemQ, explicit := acceptQuality(accept, errs.MIMEApplicationProblemJSON)
+	if !explicit || problemQ == 0 {
+		return false
+	}
+	jsonQ, _ := acceptQuality(accept, echo.MIMEApplicationJSON)
+	return problemQ >= jsonQ
+}
+
+// acceptQuality returns the quality accept gives mediaType, taken from the
+// most specific matching range, and whether that range names it exactly.
+func acceptQuality(accept, mediaType string) (float64, bool) {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+
+		var rangeSpecificity int
+		switch mediaRange {
+		case mediaType:
+			rangeSpecificity = 2
+		case mainType + "/*":
+			rangeSpecificity = 1
+		case "*/*":
+			rangeSpecificity = 0
+		default:
+			continue
+		}
+		if rangeSpecificity <= specificity {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		quality, specificity = q, rangeSpecificity
+	}
+
+	return quality, specificity == 2
+}
@@ -0,0 +1,27 @@
+package middlerware
+
+import "testing"
+
+func TestPrefersProblem(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/problem+json;q=0", false},
+		{"application/problem+json; q=0.0, application/json", false},
+		{"application/json, application/problem+json", true},
+		{"application/json;q=1, application/problem+json;q=0.5", false},
+		{"application/problem+json;q=0.9, application/*;q=0.5", true},
+		{"Application/Problem+JSON", true},
+	}
+
+	for _, tt := range tests {
+		if got := prefersProblem(tt.accept); got != tt.want {
+			t.Errorf("prefersProblem(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
@@ -1,15 +1,75 @@
 package middlerware
 
-import "github.com/C0deNe0/go-boiler/internal/server"
+import (
+	"sync/atomic"
 
+	"github.com/C0deNe0/go-boiler/internal/config"
+	"github.com/C0deNe0/go-boiler/internal/server"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+// DefaultRequestsPerSecond applies when server.rate_limit.requests_per_second is unset
+const DefaultRequestsPerSecond = 20
+
+// RateLimitMiddleware is a middleware.RateLimiterStore whose limits follow config reloads
 type RateLimitMiddleware struct {
 	server *server.Server
+	store  atomic.Pointer[middleware.RateLimiterMemoryStore]
 }
 
 func NewRateLimitMiddleware(s *server.Server) *RateLimitMiddleware {
-	return &RateLimitMiddleware{
+	r := &RateLimitMiddleware{
 		server: s,
 	}
+	r.store.Store(newRateLimiterStore(s.Config.Server.RateLimit))
+
+	if s.ConfigWatcher != nil {
+		s.ConfigWatcher.Subscribe(func(previous, current *config.Config) {
+			if previous.Server.RateLimit == current.Server.RateLimit {
+				return
+			}
+			// swapping the store resets per-visitor state, which is acceptable on reload
+			r.store.Store(newRateLimiterStore(current.Server.RateLimit))
+			requestsPerSecond, burst := rateLimit(current.Server.RateLimit)
+			s.Logger.Info().
+				Float64("requests_per_second", requestsPerSecond).
+				Int("burst", burst).
+				Msg("rate limit updated")
+		})
+	}
+
+	return r
+}
+
+func newRateLimiterStore(cfg config.RateLimitConfig) *middleware.RateLimiterMemoryStore {
+	requestsPerSecond, burst := rateLimit(cfg)
+
+	return middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:  rate.Limit(requestsPerSecond),
+		Burst: burst,
+	})
+}
+
+// rateLimit applies the defaults to cfg. The burst defaults to the rate and
+// is at least one, since a limiter with a zero burst rejects every request,
+// e.g. for rates below one request per second.
+func rateLimit(cfg config.RateLimitConfig) (float64, int) {
+	requestsPerSecond := cfg.RequestsPerSecond
+	if requestsPerSecond == 0 {
+		requestsPerSecond = DefaultRequestsPerSecond
+	}
+
+	burst := cfg.Burst
+	if burst == 0 {
+		burst = int(requestsPerSecond)
+	}
+	return requestsPerSecond, max(burst, 1)
+}
+
+// Allow implements middleware.RateLimiterStore
+func (r *RateLimitMiddleware) Allow(identifier string) (bool, error) {
+	return r.store.Load().Allow(identifier)
 }
 
 func (r *RateLimitMiddleware) RecordRateLimitHit(endpoint string) {
@@ -0,0 +1,38 @@
+package middlerware
+
+import (
+	"testing"
+
+	"github.com/C0deNe0/go-boiler/internal/config"
+)
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.RateLimitConfig
+		wantRate  float64
+		wantBurst int
+	}{
+		{name: "defaults", cfg: config.RateLimitConfig{}, wantRate: DefaultRequestsPerSecond, wantBurst: DefaultRequestsPerSecond},
+		{name: "burst from rate", cfg: config.RateLimitConfig{RequestsPerSecond: 5.5}, wantRate: 5.5, wantBurst: 5},
+		{name: "fractional rate", cfg: config.RateLimitConfig{RequestsPerSecond: 0.5}, wantRate: 0.5, wantBurst: 1},
+		{name: "configured burst", cfg: config.RateLimitConfig{RequestsPerSecond: 0.5, Burst: 3}, wantRate: 0.5, wantBurst: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, burst := rateLimit(tt.cfg)
+			if rate != tt.wantRate || burst != tt.wantBurst {
+				t.Errorf("rateLimit(%+v) = %v, %d, want %v, %d", tt.cfg, rate, burst, tt.wantRate, tt.wantBurst)
+			}
+		})
+	}
+}
+
+func TestFractionalRateAllowsFirstRequest(t *testing.T) {
+	store := newRateLimiterStore(config.RateLimitConfig{RequestsPerSecond: 0.2})
+
+	if allowed, err := store.Allow("127.0.0.1"); err != nil || !allowed {
+		t.Errorf("Allow() = %t, %v, want the first request allowed", allowed, err)
+	}
+}
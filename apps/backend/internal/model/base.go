@@ -3,6 +3,7 @@ package model
 import (
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 )
 
@@ -18,12 +19,58 @@ type BaseWithUpdatedAt struct {
 	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
 }
 
+// BaseWithSoftDelete marks rows the repository hides instead of deleting.
+type BaseWithSoftDelete struct {
+	DeletedAt *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+// BaseWithAudit records the users that created and last changed a row.
+type BaseWithAudit struct {
+	CreatedBy *string `json:"createdBy,omitempty" db:"created_by"`
+	UpdatedBy *string `json:"updatedBy,omitempty" db:"updated_by"`
+}
+
+// BaseWithVersion enables optimistic concurrency: the repository only
+// updates a row whose version still matches and increments it on every write.
+type BaseWithVersion struct {
+	Version int64 `json:"version" db:"version"`
+}
+
+// GetVersion lets responses carry the version as their ETag.
+func (b BaseWithVersion) GetVersion() int64 {
+	return b.Version
+}
+
 type Base struct {
 	BaseWithId
 	BaseWithCreatedAt
 	BaseWithUpdatedAt
 }
 
+// CursorQuery binds keyset pagination query parameters, e.g.
+// ?cursor=<token>&limit=20&sort=-created_at,name. Embed it in list requests.
+type CursorQuery struct {
+	Cursor string `query:"cursor" validate:"omitempty,max=2048"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Sort   string `query:"sort" validate:"omitempty,max=256"`
+}
+
+// validate is shared so its struct cache survives across requests.
+var validate = validator.New()
+
+func (q *CursorQuery) Validate() error {
+	return validate.Struct(q)
+}
+
+// CursorPage is one page of a keyset-paginated list. A cursor is empty when
+// there is no page in that direction.
+type CursorPage[T interface{}] struct {
+	Data       []T    `json:"data"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
 type PaginatedResponse[T interface{}] struct {
 	Data       []T `json:"data"`
 	Page       int `json:"page"`
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/errs"
+	"github.com/C0deNe0/go-boiler/internal/lib/cursor"
+	"github.com/C0deNe0/go-boiler/internal/lib/filter"
+	"github.com/C0deNe0/go-boiler/internal/model"
+	"github.com/C0deNe0/go-boiler/internal/sqlerr"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListByCursor returns the page after, or before, q.Cursor using keyset
+// pagination, which stays fast and stable on large tables because it needs
+// neither OFFSET nor COUNT(*). Sorting by a nullable column is rejected. The
+// repository must be created WithCursors. f may be nil; its sort, when set,
+// replaces q.Sort.
+func (r *Repository[T]) ListByCursor(ctx context.Context, q model.CursorQuery, f *filter.Query) (*model.CursorPage[T], error) {
+	if r.options.cursors == nil {
+		return nil, errors.New("repository: ListByCursor requires WithCursors")
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
+	sort := q.Sort
+	if f != nil && f.Sort != "" {
+		sort = f.Sort
+	}
+	keys, err := r.parseSort(sort)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkCursorSort(keys); err != nil {
+		return nil, err
+	}
+
+	var (
+		conditions []string
+		args       []any
+		backward   bool
+	)
+	if q.Cursor != "" {
+		cur, err := r.options.cursors.Decode(q.Cursor)
+		if err != nil || cur.Sort != sort || len(cur.Values) != len(keys) {
+			return nil, errs.NewBadRequestError("invalid cursor", true, nil,
+				[]errs.FieldError{{Field: "cursor", Error: "is invalid or was issued for a different sort", Key: "cursor.invalid_field"}}, nil).
+				WithMessageKey("cursor.invalid")
+		}
+
+		backward = cur.Backward
+		for _, value := range cur.Values {
+			args = append(args, value)
+		}
+		conditions = append(conditions, keysetCondition(keys, backward))
+	}
+
+	filterWhere, filterArgs := f.Where(len(args) + 1)
+	args = append(args, filterArgs...)
+	where := r.where(ctx, append(conditions, filterWhere)...)
+	args = append(args, limit+1)
+
+	sql := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT $%d",
+		r.selectList(), r.tableName(), where, orderBy(keys, backward), len(args))
+
+	rows, err := r.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, sqlerr.HandleError(err)
+	}
+	data, err := pgx.CollectRows(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		return nil, sqlerr.HandleError(err)
+	}
+
+	more := len(data) > limit
+	if more {
+		data = data[:limit]
+	}
+	if backward {
+		slices.Reverse(data)
+	}
+
+	page := &model.CursorPage[T]{Data: data, Limit: limit}
+	if len(data) == 0 {
+		return page, nil
+	}
+
+	// a backward page was reached from a later one, a forward page with a
+	// cursor from an earlier one
+	hasNext := more || backward
+	hasPrev := (more && backward) || (!backward && q.Cursor != "")
+
+	if hasNext {
+		if page.NextCursor, err = r.encodeCursor(sort, keys, &data[len(data)-1], false); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if page.PrevCursor, err = r.encodeCursor(sort, keys, &data[0], true); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// checkCursorSort rejects nullable sort columns: NULL compares as unknown
+// in the keyset condition, so pages would fail to bind or silently skip rows.
+func (r *Repository[T]) checkCursorSort(keys []sortKey) error {
+	for _, key := range keys {
+		if !key.nullable {
+			continue
+		}
+
+		var allowed []string
+		for _, col := range r.columns {
+			if !col.nullable {
+				allowed = append(allowed, col.name)
+			}
+		}
+		list := strings.Join(allowed, ", ")
+
+		return errs.NewBadRequestError(fmt.Sprintf("cannot paginate by %q, sort by one of: %s", key.name, list), true, nil,
+			[]errs.FieldError{{Field: "sort", Error: "must be one of: " + list, Key: "cursor.unsortable_field", Args: []any{list}}}, nil).
+			WithMessageKey("cursor.unsortable", key.name, list)
+	}
+	return nil
+}
+
+// keysetCondition selects the rows strictly after the cursor position in
+// the order of keys, or strictly before it when backward is set. Cursor
+// values are bound as $1..$n in key order. Each key may have its own
+// direction, so the condition is expanded rather than a row comparison:
+// (a > $1) OR (a = $1 AND b < $2) OR ...
+func keysetCondition(keys []sortKey, backward bool) string {
+	alternatives := make([]string, 0, len(keys))
+	for i, key := range keys {
+		terms := make([]string, 0, i+1)
+		for j := range i {
+			terms = append(terms, fmt.Sprintf("%s = $%d", pgx.Identifier{keys[j].name}.Sanitize(), j+1))
+		}
+
+		op := ">"
+		if key.desc != backward {
+			op = "<"
+		}
+		terms = append(terms, fmt.Sprintf("%s %s $%d", pgx.Identifier{key.name}.Sanitize(), op, i+1))
+
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+func (r *Repository[T]) encodeCursor(sort string, keys []sortKey, row *T, backward bool) (string, error) {
+	v := reflect.ValueOf(row).Elem()
+
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, cursorValue(v.FieldByIndex(key.index)))
+	}
+
+	return r.options.cursors.Encode(cursor.Cursor{
+		Sort:     sort,
+		Values:   values,
+		Backward: backward,
+	})
+}
+
+// cursorValue renders a column value in a text form Postgres parses back
+// into the column type.
+func cursorValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
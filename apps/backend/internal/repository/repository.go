@@ -0,0 +1,470 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/C0deNe0/go-boiler/internal/database"
+	"github.com/C0deNe0/go-boiler/internal/errs"
+	"github.com/C0deNe0/go-boiler/internal/lib/cursor"
+	"github.com/C0deNe0/go-boiler/internal/lib/filter"
+	"github.com/C0deNe0/go-boiler/internal/model"
+	"github.com/C0deNe0/go-boiler/internal/sqlerr"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+
+	idColumn        = "id"
+	createdAtColumn = "created_at"
+	updatedAtColumn = "updated_at"
+	deletedAtColumn = "deleted_at"
+	createdByColumn = "created_by"
+	updatedByColumn = "updated_by"
+	versionColumn   = "version"
+)
+
+// ListOptions selects a page of rows for Repository.List.
+type ListOptions struct {
+	// Page starts at 1; zero means the first page.
+	Page int
+	// Limit is capped at MaxPageLimit; zero means DefaultPageLimit.
+	Limit int
+	// Sort is a column name, prefixed with "-" for descending order.
+	// It defaults to newest first when the table has created_at.
+	Sort string
+	// Filter restricts the rows; its sort applies when Sort is empty.
+	Filter *filter.Query
+}
+
+type column struct {
+	name     string
+	index    []int
+	nullable bool
+}
+
+// Option configures a Repository.
+type Option func(*options)
+
+type options struct {
+	cursors *cursor.Codec
+}
+
+// WithCursors enables ListByCursor, signing cursors with codec.
+func WithCursors(codec *cursor.Codec) Option {
+	return func(o *options) {
+		o.cursors = codec
+	}
+}
+
+// Repository implements create, read, update, delete and list for a table
+// whose rows scan into T by their `db` tags, as model.Base does. created_at
+// and updated_at are set by the database, and a zero ID is generated on create.
+//
+// Tables with the model.BaseWithSoftDelete column are soft deleted: deleted
+// rows are hidden unless the context allows them with IncludeDeleted. Tables
+// with the model.BaseWithAudit columns record the user from the request context,
+// and tables with the model.BaseWithVersion column use optimistic concurrency.
+//
+// Errors are converted with sqlerr.HandleError; declare the errors for the
+// table's constraints with sqlerr.RegisterConstraints in an init function.
+type Repository[T any] struct {
+	db      *database.Database
+	table   string
+	columns []column
+	byName  map[string]column
+	options options
+}
+
+// NewRepository maps T onto table. It panics when T is not a struct with an
+// id column, since that is a programming error.
+func NewRepository[T any](db *database.Database, table string, opts ...Option) *Repository[T] {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("repository: %s is not a struct", t))
+	}
+
+	r := &Repository[T]{
+		db:      db,
+		table:   table,
+		columns: structColumns(t, nil),
+		byName:  make(map[string]column),
+	}
+	for _, col := range r.columns {
+		r.byName[col.name] = col
+	}
+	for _, opt := range opts {
+		opt(&r.options)
+	}
+	if _, ok := r.byName[idColumn]; !ok {
+		panic(fmt.Sprintf("repository: %s has no %q column", t, idColumn))
+	}
+
+	return r
+}
+
+// structColumns lists the columns of t the way pgx.RowToStructByName maps
+// them: the db tag, else the field name, flattening embedded structs.
+func structColumns(t reflect.Type, index []int) []column {
+	var columns []column
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		tag, hasTag := field.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct {
+			columns = append(columns, structColumns(field.Type, fieldIndex)...)
+			continue
+		}
+
+		name := strings.SplitN(tag, ",", 2)[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		columns = append(columns, column{name: name, index: fieldIndex, nullable: nullable(field.Type)})
+	}
+	return columns
+}
+
+// nullable reports whether a field of type t can hold NULL: pointers and
+// the like, and sql.NullString-style structs with a Valid field.
+func nullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return true
+	case reflect.Struct:
+		valid, ok := t.FieldByName("Valid")
+		return ok && valid.Type.Kind() == reflect.Bool
+	default:
+		return false
+	}
+}
+
+func (r *Repository[T]) has(name string) bool {
+	_, ok := r.byName[name]
+	return ok
+}
+
+func (r *Repository[T]) selectList() string {
+	names := make([]string, 0, len(r.columns))
+	for _, col := range r.columns {
+		names = append(names, pgx.Identifier{col.name}.Sanitize())
+	}
+	return strings.Join(names, ", ")
+}
+
+func (r *Repository[T]) tableName() string {
+	return pgx.Identifier{r.table}.Sanitize()
+}
+
+// notFound wraps pgx.ErrNoRows so that sqlerr.HandleError names the entity.
+func (r *Repository[T]) notFound() error {
+	return fmt.Errorf("table:%s:%w", r.table, pgx.ErrNoRows)
+}
+
+func (r *Repository[T]) collectOne(rows pgx.Rows, err error) (*T, error) {
+	if err != nil {
+		return nil, sqlerr.HandleError(err)
+	}
+
+	entity, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[T])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sqlerr.HandleError(r.notFound())
+	}
+	if err != nil {
+		return nil, sqlerr.HandleError(err)
+	}
+	return &entity, nil
+}
+
+// Create inserts entity and returns the stored row.
+func (r *Repository[T]) Create(ctx context.Context, entity *T) (*T, error) {
+	sql, args := r.insertStatement(ctx, entity)
+
+	rows, err := r.db.Querier(ctx).Query(ctx, sql, args...)
+	return r.collectOne(rows, err)
+}
+
+// insertStatement builds the INSERT of Create, generating a zero UUID id.
+func (r *Repository[T]) insertStatement(ctx context.Context, entity *T) (string, []any) {
+	v := reflect.ValueOf(entity).Elem()
+
+	if id := v.FieldByIndex(r.byName[idColumn].index); id.IsZero() && id.Type() == reflect.TypeFor[uuid.UUID]() {
+		id.Set(reflect.ValueOf(uuid.New()))
+	}
+
+	var (
+		names  []string
+		values []string
+		args   []any
+	)
+	for _, col := range r.columns {
+		names = append(names, pgx.Identifier{col.name}.Sanitize())
+		switch col.name {
+		case createdAtColumn, updatedAtColumn:
+			values = append(values, "NOW()")
+			continue
+		case versionColumn:
+			values = append(values, "1")
+			continue
+		case createdByColumn, updatedByColumn:
+			args = append(args, actor(ctx))
+		default:
+			args = append(args, v.FieldByIndex(col.index).Interface())
+		}
+		values = append(values, fmt.Sprintf("$%d", len(args)))
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+		r.tableName(), strings.Join(names, ", "), strings.Join(values, ", "), r.selectList())
+	return sql, args
+}
+
+// GetByID returns the row with the given ID or a not found error.
+func (r *Repository[T]) GetByID(ctx context.Context, id uuid.UUID) (*T, error) {
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1%s",
+		r.selectList(), r.tableName(), pgx.Identifier{idColumn}.Sanitize(), r.andNotDeleted(ctx))
+
+	rows, err := r.db.Reader(ctx).Query(ctx, sql, id)
+	return r.collectOne(rows, err)
+}
+
+// Update overwrites every column of the row with entity's ID, except
+// created_at, created_by and deleted_at, and returns the stored row.
+// Soft-deleted rows are not found. For versioned tables the update only
+// applies when entity's version is still current; otherwise it fails with
+// a 409 errs.CodeVersionConflict error, unless ctx allows AnyVersion.
+func (r *Repository[T]) Update(ctx context.Context, entity *T) (*T, error) {
+	sql, args, id := r.updateStatement(ctx, entity)
+
+	rows, err := r.db.Querier(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, sqlerr.HandleError(err)
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[T])
+	if errors.Is(err, pgx.ErrNoRows) && r.checksVersion(ctx) {
+		return nil, r.versionConflict(ctx, id)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sqlerr.HandleError(r.notFound())
+	}
+	if err != nil {
+		return nil, sqlerr.HandleError(err)
+	}
+	return &updated, nil
+}
+
+// updateStatement builds the UPDATE of Update and returns the entity's id.
+func (r *Repository[T]) updateStatement(ctx context.Context, entity *T) (string, []any, any) {
+	v := reflect.ValueOf(entity).Elem()
+
+	var (
+		assignments []string
+		args        []any
+	)
+	for _, col := range r.columns {
+		switch col.name {
+		case idColumn, createdAtColumn, createdByColumn, deletedAtColumn:
+			continue
+		case updatedAtColumn:
+			assignments = append(assignments, pgx.Identifier{col.name}.Sanitize()+" = NOW()")
+		case updatedByColumn:
+			args = append(args, actor(ctx))
+			assignments = append(assignments, fmt.Sprintf("%s = $%d", pgx.Identifier{col.name}.Sanitize(), len(args)))
+		case versionColumn:
+			assignments = append(assignments, fmt.Sprintf("%[1]s = %[1]s + 1", pgx.Identifier{col.name}.Sanitize()))
+		default:
+			args = append(args, v.FieldByIndex(col.index).Interface())
+			assignments = append(assignments, fmt.Sprintf("%s = $%d", pgx.Identifier{col.name}.Sanitize(), len(args)))
+		}
+	}
+	id := v.FieldByIndex(r.byName[idColumn].index).Interface()
+	args = append(args, id)
+	conditions := fmt.Sprintf("%s = $%d%s", pgx.Identifier{idColumn}.Sanitize(), len(args), r.andNotDeleted(ctx))
+
+	if r.checksVersion(ctx) {
+		args = append(args, v.FieldByIndex(r.byName[versionColumn].index).Interface())
+		conditions += fmt.Sprintf(" AND %s = $%d", pgx.Identifier{versionColumn}.Sanitize(), len(args))
+	}
+
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s RETURNING %s",
+		r.tableName(), strings.Join(assignments, ", "), conditions, r.selectList())
+	return sql, args, id
+}
+
+func (r *Repository[T]) versioned() bool {
+	return r.has(versionColumn)
+}
+
+type anyVersionContextKey struct{}
+
+// AnyVersion makes updates through ctx overwrite a versioned row whatever its
+// current version, as a request with "If-Match: *" asks for. The version is
+// still incremented.
+func AnyVersion(ctx context.Context) context.Context {
+	return context.WithValue(ctx, anyVersionContextKey{}, true)
+}
+
+// checksVersion reports whether an update must match the entity's version.
+func (r *Repository[T]) checksVersion(ctx context.Context) bool {
+	anyVersion, _ := ctx.Value(anyVersionContextKey{}).(bool)
+	return r.versioned() && !anyVersion
+}
+
+// versionConflict tells a stale version apart from a missing row after an
+// update matched nothing.
+func (r *Repository[T]) versionConflict(ctx context.Context, id any) error {
+	sql := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE %s = $1%s)",
+		r.tableName(), pgx.Identifier{idColumn}.Sanitize(), r.andNotDeleted(ctx))
+
+	var exists bool
+	if err := r.db.Querier(ctx).QueryRow(ctx, sql, id).Scan(&exists); err != nil {
+		return sqlerr.HandleError(err)
+	}
+	if !exists {
+		return sqlerr.HandleError(r.notFound())
+	}
+
+	return errs.NewVersionConflictError()
+}
+
+// Delete removes the row with the given ID or returns a not found error.
+// Rows of soft-delete tables are only marked deleted; see Purge.
+func (r *Repository[T]) Delete(ctx context.Context, id uuid.UUID) error {
+	if r.softDeletes() {
+		return r.softDelete(ctx, id)
+	}
+	return r.Purge(ctx, id)
+}
+
+// Purge removes the row with the given ID permanently, even from a
+// soft-delete table, or returns a not found error.
+func (r *Repository[T]) Purge(ctx context.Context, id uuid.UUID) error {
+	sql := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", r.tableName(), pgx.Identifier{idColumn}.Sanitize())
+
+	tag, err := r.db.Querier(ctx).Exec(ctx, sql, id)
+	if err != nil {
+		return sqlerr.HandleError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sqlerr.HandleError(r.notFound())
+	}
+	return nil
+}
+
+// List returns one page of rows together with the total row count.
+func (r *Repository[T]) List(ctx context.Context, opts ListOptions) (*model.PaginatedResponse[T], error) {
+	page := max(opts.Page, 1)
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
+	sort := opts.Sort
+	if sort == "" && opts.Filter != nil {
+		sort = opts.Filter.Sort
+	}
+	keys, err := r.parseSort(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	filterWhere, args := opts.Filter.Where(1)
+	where := r.where(ctx, filterWhere)
+
+	querier := r.db.Reader(ctx)
+
+	var total int
+	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", r.tableName(), where)
+	if err := querier.QueryRow(ctx, countSQL, args...).Scan(&total); err != nil {
+		return nil, sqlerr.HandleError(err)
+	}
+
+	sql := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT $%d OFFSET $%d",
+		r.selectList(), r.tableName(), where, orderBy(keys, false), len(args)+1, len(args)+2)
+
+	rows, err := querier.Query(ctx, sql, append(args, limit, (page-1)*limit)...)
+	if err != nil {
+		return nil, sqlerr.HandleError(err)
+	}
+	data, err := pgx.CollectRows(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		return nil, sqlerr.HandleError(err)
+	}
+
+	return &model.PaginatedResponse[T]{
+		Data:       data,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
+
+// sortKey is one column of an ORDER BY clause.
+type sortKey struct {
+	column
+	desc bool
+}
+
+// parseSort turns a sort parameter such as "-created_at,name" into sort
+// keys, only accepting mapped columns. The id is appended to break ties so
+// that pages neither overlap nor skip rows.
+func (r *Repository[T]) parseSort(sort string) ([]sortKey, error) {
+	if sort == "" && r.has(createdAtColumn) {
+		sort = "-" + createdAtColumn
+	}
+
+	var keys []sortKey
+	hasID := false
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		name, desc := strings.CutPrefix(field, "-")
+		col, ok := r.byName[name]
+		if !ok {
+			return nil, errs.NewBadRequestError(fmt.Sprintf("cannot sort by %q", name), true, nil,
+				[]errs.FieldError{{Field: "sort", Error: "is not a sortable field", Key: "filter.unsortable_field"}}, nil).
+				WithMessageKey("filter.invalid_sort", name)
+		}
+
+		keys = append(keys, sortKey{column: col, desc: desc})
+		hasID = hasID || name == idColumn
+	}
+
+	if !hasID {
+		desc := len(keys) > 0 && keys[len(keys)-1].desc
+		keys = append(keys, sortKey{column: r.byName[idColumn], desc: desc})
+	}
+	return keys, nil
+}
+
+// orderBy renders keys, reversing every direction when reverse is set.
+func orderBy(keys []sortKey, reverse bool) string {
+	clauses := make([]string, 0, len(keys))
+	for _, key := range keys {
+		direction := "ASC"
+		if key.desc != reverse {
+			direction = "DESC"
+		}
+		clauses = append(clauses, pgx.Identifier{key.name}.Sanitize()+" "+direction)
+	}
+	return strings.Join(clauses, ", ")
+}
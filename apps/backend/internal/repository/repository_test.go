@@ -0,0 +1,359 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/C0deNe0/go-boiler/internal/errs"
+	"github.com/C0deNe0/go-boiler/internal/lib/cursor"
+	"github.com/C0deNe0/go-boiler/internal/lib/identity"
+	"github.com/C0deNe0/go-boiler/internal/model"
+	"github.com/google/uuid"
+)
+
+type task struct {
+	model.Base
+	Title    string  `db:"title"`
+	Priority int     `db:"priority"`
+	Notes    *string `db:"notes"`
+	Draft    bool    `db:"-"`
+	Owner    string
+	internal string //nolint:unused // unexported fields are not columns
+}
+
+type auditedTask struct {
+	model.Base
+	model.BaseWithSoftDelete
+	model.BaseWithAudit
+	model.BaseWithVersion
+	Title string `db:"title"`
+}
+
+func columnNames[T any](r *Repository[T]) []string {
+	names := make([]string, 0, len(r.columns))
+	for _, col := range r.columns {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+func TestNewRepositoryColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{
+			name: "embedded structs are flattened",
+			got:  columnNames(NewRepository[task](nil, "tasks")),
+			want: []string{"id", "created_at", "updated_at", "title", "priority", "notes", "owner"},
+		},
+		{
+			name: "base columns",
+			got:  columnNames(NewRepository[auditedTask](nil, "tasks")),
+			want: []string{"id", "created_at", "updated_at", "deleted_at", "created_by", "updated_by", "version", "title"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !slices.Equal(tt.got, tt.want) {
+				t.Errorf("columns = %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRepositoryPanics(t *testing.T) {
+	type noID struct {
+		Title string `db:"title"`
+	}
+
+	tests := []struct {
+		name string
+		new  func()
+	}{
+		{name: "not a struct", new: func() { NewRepository[string](nil, "strings") }},
+		{name: "no id column", new: func() { NewRepository[noID](nil, "things") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("NewRepository did not panic")
+				}
+			}()
+			tt.new()
+		})
+	}
+}
+
+func TestInsertStatement(t *testing.T) {
+	notes := "n"
+	id := uuid.MustParse("5b0e6a8c-9e43-4b7e-9d2f-4f3f6f1f7a10")
+
+	t.Run("plain table", func(t *testing.T) {
+		r := NewRepository[task](nil, "tasks")
+		entity := &task{Base: model.Base{BaseWithId: model.BaseWithId{ID: id}}, Title: "t", Priority: 2, Notes: &notes, Owner: "o"}
+
+		sql, args := r.insertStatement(context.Background(), entity)
+
+		wantSQL := `INSERT INTO "tasks" ("id", "created_at", "updated_at", "title", "priority", "notes", "owner") ` +
+			`VALUES ($1, NOW(), NOW(), $2, $3, $4, $5) ` +
+			`RETURNING "id", "created_at", "updated_at", "title", "priority", "notes", "owner"`
+		if sql != wantSQL {
+			t.Errorf("sql =\n%s\nwant\n%s", sql, wantSQL)
+		}
+		if want := []any{id, "t", 2, &notes, "o"}; !reflect.DeepEqual(args, want) {
+			t.Errorf("args = %#v, want %#v", args, want)
+		}
+	})
+
+	t.Run("generates a zero id", func(t *testing.T) {
+		r := NewRepository[task](nil, "tasks")
+		entity := &task{}
+
+		_, args := r.insertStatement(context.Background(), entity)
+
+		if entity.ID == uuid.Nil {
+			t.Fatal("id was not generated")
+		}
+		if args[0] != entity.ID {
+			t.Errorf("bound id = %v, want %v", args[0], entity.ID)
+		}
+	})
+
+	t.Run("audit and version columns", func(t *testing.T) {
+		r := NewRepository[auditedTask](nil, "tasks")
+		ctx := identity.WithUserID(context.Background(), "user_1")
+		entity := &auditedTask{Base: model.Base{BaseWithId: model.BaseWithId{ID: id}}, Title: "t"}
+
+		sql, args := r.insertStatement(ctx, entity)
+
+		wantSQL := `INSERT INTO "tasks" ("id", "created_at", "updated_at", "deleted_at", "created_by", "updated_by", "version", "title") ` +
+			`VALUES ($1, NOW(), NOW(), $2, $3, $4, 1, $5) ` +
+			`RETURNING "id", "created_at", "updated_at", "deleted_at", "created_by", "updated_by", "version", "title"`
+		if sql != wantSQL {
+			t.Errorf("sql =\n%s\nwant\n%s", sql, wantSQL)
+		}
+		if want := []any{id, (*time.Time)(nil), "user_1", "user_1", "t"}; !reflect.DeepEqual(args, want) {
+			t.Errorf("args = %#v, want %#v", args, want)
+		}
+	})
+
+	t.Run("no authenticated user", func(t *testing.T) {
+		r := NewRepository[auditedTask](nil, "tasks")
+
+		_, args := r.insertStatement(context.Background(), &auditedTask{Title: "t"})
+
+		if args[2] != nil || args[3] != nil {
+			t.Errorf("actor args = %v, %v, want nil", args[2], args[3])
+		}
+	})
+}
+
+func TestUpdateStatement(t *testing.T) {
+	id := uuid.MustParse("5b0e6a8c-9e43-4b7e-9d2f-4f3f6f1f7a10")
+
+	t.Run("plain table", func(t *testing.T) {
+		r := NewRepository[task](nil, "tasks")
+		entity := &task{Base: model.Base{BaseWithId: model.BaseWithId{ID: id}}, Title: "t", Priority: 2, Owner: "o"}
+
+		sql, args, gotID := r.updateStatement(context.Background(), entity)
+
+		wantSQL := `UPDATE "tasks" SET "updated_at" = NOW(), "title" = $1, "priority" = $2, "notes" = $3, "owner" = $4 ` +
+			`WHERE "id" = $5 ` +
+			`RETURNING "id", "created_at", "updated_at", "title", "priority", "notes", "owner"`
+		if sql != wantSQL {
+			t.Errorf("sql =\n%s\nwant\n%s", sql, wantSQL)
+		}
+		if want := []any{"t", 2, (*string)(nil), "o", id}; !reflect.DeepEqual(args, want) {
+			t.Errorf("args = %#v, want %#v", args, want)
+		}
+		if gotID != id {
+			t.Errorf("id = %v, want %v", gotID, id)
+		}
+	})
+
+	t.Run("versioned soft-delete table", func(t *testing.T) {
+		r := NewRepository[auditedTask](nil, "tasks")
+		ctx := identity.WithUserID(context.Background(), "user_1")
+		entity := &auditedTask{
+			Base:            model.Base{BaseWithId: model.BaseWithId{ID: id}},
+			BaseWithVersion: model.BaseWithVersion{Version: 7},
+			Title:           "t",
+		}
+
+		sql, args, _ := r.updateStatement(ctx, entity)
+
+		wantSQL := `UPDATE "tasks" SET "updated_at" = NOW(), "updated_by" = $1, "version" = "version" + 1, "title" = $2 ` +
+			`WHERE "id" = $3 AND "deleted_at" IS NULL AND "version" = $4 ` +
+			`RETURNING "id", "created_at", "updated_at", "deleted_at", "created_by", "updated_by", "version", "title"`
+		if sql != wantSQL {
+			t.Errorf("sql =\n%s\nwant\n%s", sql, wantSQL)
+		}
+		if want := []any{"user_1", "t", id, int64(7)}; !reflect.DeepEqual(args, want) {
+			t.Errorf("args = %#v, want %#v", args, want)
+		}
+	})
+}
+
+func TestUpdateStatementAnyVersion(t *testing.T) {
+	r := NewRepository[auditedTask](nil, "tasks")
+	entity := &auditedTask{BaseWithVersion: model.BaseWithVersion{Version: 7}, Title: "t"}
+
+	sql, args, _ := r.updateStatement(AnyVersion(context.Background()), entity)
+
+	wantSQL := `UPDATE "tasks" SET "updated_at" = NOW(), "updated_by" = $1, "version" = "version" + 1, "title" = $2 ` +
+		`WHERE "id" = $3 AND "deleted_at" IS NULL ` +
+		`RETURNING "id", "created_at", "updated_at", "deleted_at", "created_by", "updated_by", "version", "title"`
+	if sql != wantSQL {
+		t.Errorf("sql =\n%s\nwant\n%s", sql, wantSQL)
+	}
+	if len(args) != 3 {
+		t.Errorf("args = %#v, want no version argument", args)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	plain := NewRepository[task](nil, "tasks")
+
+	tests := []struct {
+		name    string
+		sort    string
+		want    string
+		wantErr bool
+	}{
+		{name: "default is newest first", want: `"created_at" DESC, "id" DESC`},
+		{name: "ascending", sort: "title", want: `"title" ASC, "id" ASC`},
+		{name: "descending", sort: "-priority", want: `"priority" DESC, "id" DESC`},
+		{name: "several columns", sort: "-priority, title", want: `"priority" DESC, "title" ASC, "id" ASC`},
+		{name: "explicit id", sort: "-id", want: `"id" DESC`},
+		{name: "empty entries", sort: "title,,", want: `"title" ASC, "id" ASC`},
+		{name: "unknown column", sort: "password", wantErr: true},
+		{name: "ignored field", sort: "draft", wantErr: true},
+		{name: "sql", sort: "title; drop table tasks", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := plain.parseSort(tt.sort)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseSort(%q) = %v, want an error", tt.sort, keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSort(%q) returned %v", tt.sort, err)
+			}
+			if got := orderBy(keys, false); got != tt.want {
+				t.Errorf("orderBy = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderByReverse(t *testing.T) {
+	r := NewRepository[task](nil, "tasks")
+
+	keys, err := r.parseSort("-priority,title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := orderBy(keys, true), `"priority" ASC, "title" DESC, "id" DESC`; got != want {
+		t.Errorf("orderBy(reverse) = %s, want %s", got, want)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	plain := NewRepository[task](nil, "tasks")
+	softDeleted := NewRepository[auditedTask](nil, "tasks")
+
+	tests := []struct {
+		name       string
+		where      string
+		wantClause string
+	}{
+		{name: "nothing", where: plain.where(context.Background()), wantClause: ""},
+		{name: "empty conditions", where: plain.where(context.Background(), "", ""), wantClause: ""},
+		{name: "conditions", where: plain.where(context.Background(), `"a" = $1`, "", `"b" = $2`), wantClause: ` WHERE "a" = $1 AND "b" = $2`},
+		{name: "hides deleted rows", where: softDeleted.where(context.Background()), wantClause: ` WHERE "deleted_at" IS NULL`},
+		{
+			name:       "hides deleted rows with conditions",
+			where:      softDeleted.where(context.Background(), `"a" = $1`),
+			wantClause: ` WHERE "a" = $1 AND "deleted_at" IS NULL`,
+		},
+		{name: "include deleted", where: softDeleted.where(IncludeDeleted(context.Background())), wantClause: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.where != tt.wantClause {
+				t.Errorf("where = %q, want %q", tt.where, tt.wantClause)
+			}
+		})
+	}
+}
+
+// pageErr drops the page so table tests can keep just the error.
+func pageErr[T any](_ *model.CursorPage[T], err error) error {
+	return err
+}
+
+func TestListByCursorRejectsNullableSort(t *testing.T) {
+	codec := cursor.NewCodec([]byte("test-key"))
+	plain := NewRepository[task](nil, "tasks", WithCursors(codec))
+	audited := NewRepository[auditedTask](nil, "tasks", WithCursors(codec))
+
+	tests := []struct {
+		name    string
+		err     error
+		allowed string
+	}{
+		{
+			name:    "pointer column",
+			err:     pageErr(plain.ListByCursor(context.Background(), model.CursorQuery{Sort: "notes"}, nil)),
+			allowed: "id, created_at, updated_at, title, priority, owner",
+		},
+		{
+			name:    "deleted_at",
+			err:     pageErr(audited.ListByCursor(context.Background(), model.CursorQuery{Sort: "-deleted_at"}, nil)),
+			allowed: "id, created_at, updated_at, version, title",
+		},
+		{
+			name:    "audit column after a valid one",
+			err:     pageErr(audited.ListByCursor(context.Background(), model.CursorQuery{Sort: "title,created_by"}, nil)),
+			allowed: "id, created_at, updated_at, version, title",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var httpErr *errs.HTTPError
+			if !errors.As(tt.err, &httpErr) {
+				t.Fatalf("ListByCursor returned %v, want an HTTP error", tt.err)
+			}
+			if httpErr.Status != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", httpErr.Status, http.StatusBadRequest)
+			}
+			if len(httpErr.Errors) != 1 || httpErr.Errors[0].Args[0] != tt.allowed {
+				t.Errorf("field errors = %+v, want the allowed sorts %q", httpErr.Errors, tt.allowed)
+			}
+		})
+	}
+
+	keys, err := plain.parseSort("-created_at,title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.checkCursorSort(keys); err != nil {
+		t.Errorf("checkCursorSort(NOT NULL columns) = %v", err)
+	}
+}
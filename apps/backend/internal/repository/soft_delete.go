@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/C0deNe0/go-boiler/internal/lib/identity"
+	"github.com/C0deNe0/go-boiler/internal/sqlerr"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type includeDeletedContextKey struct{}
+
+// IncludeDeleted makes repository reads through ctx return soft-deleted rows too.
+func IncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedContextKey{}, true)
+}
+
+func includesDeleted(ctx context.Context) bool {
+	include, _ := ctx.Value(includeDeletedContextKey{}).(bool)
+	return include
+}
+
+// actor is the user recorded in created_by and updated_by. RequireAuth puts
+// the authenticated user in the request context with identity.WithUserID;
+// other writes record NULL.
+func actor(ctx context.Context) any {
+	if userID := identity.UserID(ctx); userID != "" {
+		return userID
+	}
+	return nil
+}
+
+func (r *Repository[T]) softDeletes() bool {
+	return r.has(deletedAtColumn)
+}
+
+func (r *Repository[T]) notDeleted(ctx context.Context) string {
+	if !r.softDeletes() || includesDeleted(ctx) {
+		return ""
+	}
+	return pgx.Identifier{deletedAtColumn}.Sanitize() + " IS NULL"
+}
+
+func (r *Repository[T]) andNotDeleted(ctx context.Context) string {
+	if condition := r.notDeleted(ctx); condition != "" {
+		return " AND " + condition
+	}
+	return ""
+}
+
+// where joins the non-empty conditions and the soft delete condition into a
+// WHERE clause, or returns an empty string.
+func (r *Repository[T]) where(ctx context.Context, conditions ...string) string {
+	var clauses []string
+	for _, condition := range append(conditions, r.notDeleted(ctx)) {
+		if condition != "" {
+			clauses = append(clauses, condition)
+		}
+	}
+
+	if len(clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(clauses, " AND ")
+}
+
+// touch sets updated_at and updated_by, binding the actor to arg, and
+// increments the version, for the columns the table has.
+func (r *Repository[T]) touch(arg int) string {
+	var assignments string
+	if r.versioned() {
+		assignments += fmt.Sprintf(", %[1]s = %[1]s + 1", pgx.Identifier{versionColumn}.Sanitize())
+	}
+	if r.has(updatedAtColumn) {
+		assignments += fmt.Sprintf(", %s = NOW()", pgx.Identifier{updatedAtColumn}.Sanitize())
+	}
+	if r.has(updatedByColumn) {
+		assignments += fmt.Sprintf(", %s = $%d", pgx.Identifier{updatedByColumn}.Sanitize(), arg)
+	}
+	return assignments
+}
+
+func (r *Repository[T]) softDelete(ctx context.Context, id uuid.UUID) error {
+	sql := fmt.Sprintf("UPDATE %s SET %s = NOW()%s WHERE %s = $1 AND %s IS NULL",
+		r.tableName(), pgx.Identifier{deletedAtColumn}.Sanitize(), r.touch(2),
+		pgx.Identifier{idColumn}.Sanitize(), pgx.Identifier{deletedAtColumn}.Sanitize())
+
+	args := []any{id}
+	if r.has(updatedByColumn) {
+		args = append(args, actor(ctx))
+	}
+
+	tag, err := r.db.Querier(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return sqlerr.HandleError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sqlerr.HandleError(r.notFound())
+	}
+	return nil
+}
+
+// Restore undoes a soft delete and returns the restored row. It returns a not
+// found error when the row does not exist or is not deleted.
+func (r *Repository[T]) Restore(ctx context.Context, id uuid.UUID) (*T, error) {
+	if !r.softDeletes() {
+		return nil, fmt.Errorf("repository: %s has no %s column", r.table, deletedAtColumn)
+	}
+
+	sql := fmt.Sprintf("UPDATE %s SET %s = NULL%s WHERE %s = $1 AND %s IS NOT NULL RETURNING %s",
+		r.tableName(), pgx.Identifier{deletedAtColumn}.Sanitize(), r.touch(2),
+		pgx.Identifier{idColumn}.Sanitize(), pgx.Identifier{deletedAtColumn}.Sanitize(), r.selectList())
+
+	args := []any{id}
+	if r.has(updatedByColumn) {
+		args = append(args, actor(ctx))
+	}
+
+	rows, err := r.db.Querier(ctx).Query(ctx, sql, args...)
+	return r.collectOne(rows, err)
+}
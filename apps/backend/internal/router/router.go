@@ -1,15 +1,15 @@
 package router
 
 import (
-	"net/http"
+	"time"
 
+	"github.com/C0deNe0/go-boiler/internal/errs"
 	"github.com/C0deNe0/go-boiler/internal/handler"
 	"github.com/C0deNe0/go-boiler/internal/middlerware"
 	"github.com/C0deNe0/go-boiler/internal/server"
 	"github.com/C0deNe0/go-boiler/internal/service"
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
-	"golang.org/x/time/rate"
 )
 
 func NewRouter(s *server.Server, h *handler.Handlers, services *service.Services) *echo.Echo {
@@ -21,7 +21,7 @@ func NewRouter(s *server.Server, h *handler.Handlers, services *service.Services
 	//Global Middlewares
 	router.Use(
 		echoMiddleware.RateLimiterWithConfig(echoMiddleware.RateLimiterConfig{
-			Store: echoMiddleware.NewRateLimiterMemoryStore(rate.Limit(20)),
+			Store: middlewares.RateLimit,
 			DenyHandler: func(c echo.Context, identifier string, err error) error {
 				//recording rate limit hit metrices
 				if rateLimitMiddleware := middlewares.RateLimit; rateLimitMiddleware != nil {
@@ -35,7 +35,8 @@ func NewRouter(s *server.Server, h *handler.Handlers, services *service.Services
 					Str("ip", c.RealIP()).
 					Msg("rate limit exceeded")
 
-				return echo.NewHTTPError(http.StatusBadRequest, "Rate limit exceeded")
+				return errs.NewTooManyRequestsError("Rate limit exceeded", false, time.Second).
+					WithMessageKey("error.rate_limit_exceeded")
 			},
 		}),
 		middlewares.Global.CORS(),
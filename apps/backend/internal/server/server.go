@@ -9,13 +9,19 @@ import (
 
 	"github.com/C0deNe0/go-boiler/internal/config"
 	"github.com/C0deNe0/go-boiler/internal/database"
+	"github.com/C0deNe0/go-boiler/internal/lib/cursor"
 	"github.com/C0deNe0/go-boiler/internal/lib/job"
 	loggerPkg "github.com/C0deNe0/go-boiler/internal/logger"
+	"github.com/hibiken/asynq"
 	"github.com/newrelic/go-agent/v3/integrations/nrredis-v9"
+	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
 
+// leaderElectionName identifies the election shared by all instances.
+const leaderElectionName = "server"
+
 type Server struct {
 	Config        *config.Config
 	Logger        *zerolog.Logger
@@ -23,22 +29,73 @@ type Server struct {
 	Db            *database.Database
 	Redis         *redis.Client
 	Job           *job.JobService
+	// Outbox enqueues jobs transactionally; it is set when the database is connected
+	Outbox *job.Outbox
+	// Leader elects one instance for cron-like work; it is set when the
+	// database is connected and campaigns once started
+	Leader *database.LeaderElection
+	// Cursors signs the keyset pagination cursors handed out by repositories
+	Cursors *cursor.Codec
+	// ConfigWatcher is set when runtime config reloads are enabled
+	ConfigWatcher *config.Watcher
 	httpServer    *http.Server
 }
 
-func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPkg.LoggerService) (*Server, error) {
-	db, err := database.New(cfg, logger, loggerService)
+// New creates a server connected to the database and redis. withJobs also
+// creates the job service, whose server is not started; call Job.Start to
+// consume jobs. Without it the outbox only enqueues, leaving the relay to a
+// worker.
+func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPkg.LoggerService, withJobs bool) (*Server, error) {
+	server := NewBase(cfg, logger, loggerService)
+
+	if err := server.ConnectDatabase(); err != nil {
+		return nil, err
+	}
+	server.ConnectRedis()
+	if withJobs {
+		server.InitJobs()
+	} else {
+		server.InitOutbox()
+	}
+
+	return server, nil
+}
+
+// NewBase creates a server holding only configuration and logging, so
+// commands can connect just the dependencies they need.
+func NewBase(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPkg.LoggerService) *Server {
+	cursorKey := []byte(cfg.Auth.CursorSecret.Value())
+	if len(cursorKey) == 0 {
+		cursorKey = cursor.DeriveKey(cfg.Auth.SecretKey.Value())
+	}
+
+	return &Server{
+		Config:        cfg,
+		Logger:        logger,
+		LoggerService: loggerService,
+		Cursors:       cursor.NewCodec(cursorKey),
+	}
+}
+
+func (s *Server) ConnectDatabase() error {
+	db, err := database.New(s.Config, s.Logger, s.LoggerService)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize db: %w", err)
+		return fmt.Errorf("failed to initialize db: %w", err)
 
 	}
+	s.Db = db
+	s.Leader = db.NewLeaderElection(leaderElectionName, 0)
+	return nil
+}
+
+func (s *Server) ConnectRedis() {
 	//redis client with New Relic integration
 	redisClient := redis.NewClient(&redis.Options{
-		Addr: cfg.Redis.Address,
+		Addr: s.Config.Redis.Address,
 	})
 
 	//Add New Relic Redis hooks if available
-	if loggerService != nil && loggerService.GetApplication() != nil {
+	if s.LoggerService != nil && s.LoggerService.GetApplication() != nil {
 		redisClient.AddHook(nrredis.NewHook(redisClient.Options()))
 	}
 
@@ -48,29 +105,37 @@ func New(cfg *config.Config, logger *zerolog.Logger, loggerService *loggerPkg.Lo
 	defer cancel()
 
 	if err := redisClient.Ping(ctx).Err(); err != nil {
-		logger.Error().Err(err).Msg("Failed to connect to redis, continuing without redis")
+		s.Logger.Error().Err(err).Msg("Failed to connect to redis, continuing without redis")
 
 	}
+	s.Redis = redisClient
+}
 
+func (s *Server) InitJobs() {
 	//job service
-	jobService := job.NewJobService(logger, cfg)
-	jobService.InitHandlers(cfg, logger)
+	jobService := job.NewJobService(s.Logger, s.Config)
+	jobService.InitHandlers(s.Config, s.Logger)
+	s.Job = jobService
 
-	//start job server
-	if err := jobService.Start(); err != nil {
-		return nil, err
-	}
+	s.InitOutbox()
+}
 
-	server := &Server{
-		Config:        cfg,
-		Logger:        logger,
-		LoggerService: loggerService,
-		Db:            db,
-		Redis:         redisClient,
-		Job:           jobService,
+// InitOutbox creates the outbox when the database is connected. Without
+// InitJobs it can only enqueue; its relay needs the job client.
+func (s *Server) InitOutbox() {
+	if s.Db == nil {
+		return
 	}
 
-	return server, nil
+	var client *asynq.Client
+	if s.Job != nil {
+		client = s.Job.Client
+	}
+	var app *newrelic.Application
+	if s.LoggerService != nil {
+		app = s.LoggerService.GetApplication()
+	}
+	s.Outbox = job.NewOutbox(s.Db, client, s.Logger, app)
 }
 
 func (s *Server) SetupHTTPServer(handler http.Handler) {
@@ -93,12 +158,23 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) ShutDown(ctx context.Context) error {
-	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
+	if s.httpServer != nil {
+		if err := s.httpServer.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 
+		}
+	}
+	// the relay and the election need the database, so they stop before the pool closes
+	if s.Outbox != nil {
+		s.Outbox.Stop()
+	}
+	if s.Leader != nil {
+		s.Leader.Stop()
 	}
-	if err := s.Db.Close(); err != nil {
-		return fmt.Errorf("failed to close database connection: %w", err)
+	if s.Db != nil {
+		if err := s.Db.Close(); err != nil {
+			return fmt.Errorf("failed to close database connection: %w", err)
+		}
 	}
 
 	if s.Job != nil {
@@ -8,6 +8,8 @@ import (
 
 type Services struct {
 	Auth *AuthService
+	// Job is nil in processes started without the worker; enqueue through
+	// the server outbox instead.
 	Job  *job.JobService
 }
 
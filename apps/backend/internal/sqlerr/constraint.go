@@ -0,0 +1,149 @@
+package sqlerr
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/C0deNe0/go-boiler/internal/errs"
+)
+
+// Constraint declares the error returned when a named constraint is violated.
+// Empty fields fall back to what HandleError derives from the database error.
+type Constraint struct {
+	// Code is the application error code, e.g. "USER_EMAIL_TAKEN".
+	Code string
+	// Message is shown to the user.
+	Message string
+	// Field is reported as an errs.FieldError when set.
+	Field string
+	// Status is the HTTP status; zero uses the default for the violation,
+	// 409 for unique and exclusion constraints and 400 otherwise.
+	Status int
+}
+
+// constraintKey identifies a constraint: index-backed constraint names are
+// unique per schema, but check and foreign key names only per table.
+type constraintKey struct {
+	table string
+	name  string
+}
+
+var (
+	constraintsMu sync.RWMutex
+	constraints   = map[constraintKey]Constraint{}
+)
+
+// RegisterConstraint declares the error for the constraint name on table.
+// It panics when the constraint is already registered, so two domains cannot
+// silently replace each other's errors. The code is added to the errs
+// catalog, so register from an init function for it to be exported with the
+// catalog:
+//
+//	func init() {
+//		sqlerr.RegisterConstraint("users", "users_email_key", sqlerr.Constraint{Code: "USER_EMAIL_TAKEN"})
+//	}
+func RegisterConstraint(table, name string, c Constraint) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+	registerConstraint(table, name, c)
+}
+
+// RegisterConstraints declares several constraints of table at once.
+func RegisterConstraints(table string, cs map[string]Constraint) {
+	constraintsMu.Lock()
+	defer constraintsMu.Unlock()
+	for name, c := range cs {
+		registerConstraint(table, name, c)
+	}
+}
+
+func registerConstraint(table, name string, c Constraint) {
+	key := constraintKey{table: table, name: name}
+	if _, ok := constraints[key]; ok {
+		panic(fmt.Sprintf("sqlerr: constraint %q on table %q registered twice", name, table))
+	}
+	constraints[key] = c
+
+	if c.Code != "" {
+		errs.Register(c.Code, c.Status, c.Message)
+	}
+}
+
+// lookupConstraint finds the constraint a database error was raised for.
+func lookupConstraint(sqlErr *Error) (Constraint, bool) {
+	if sqlErr.ConstraintName == "" {
+		return Constraint{}, false
+	}
+
+	constraintsMu.RLock()
+	defer constraintsMu.RUnlock()
+	c, ok := constraints[constraintKey{table: sqlErr.TableName, name: sqlErr.ConstraintName}]
+	return c, ok
+}
+
+// defaultStatus is the HTTP status for a constraint violation of kind code.
+func defaultStatus(code Code) int {
+	switch code {
+	case UniqueViolation, ExcludeViolation:
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// violation is the error HandleError derives for a constraint violation.
+type violation struct {
+	code    string
+	message userMessage
+	// field is reported with fieldError when set
+	field      string
+	fieldError userMessage
+	override   bool
+	// status replaces the default status for the violation when set
+	status int
+}
+
+// constraintError builds the error for a violation, preferring the
+// registered constraint over the derived code, message and field.
+func constraintError(sqlErr *Error, v violation) *errs.HTTPError {
+	status := v.status
+	if status == 0 {
+		status = defaultStatus(sqlErr.Code)
+	}
+
+	if c, ok := lookupConstraint(sqlErr); ok {
+		if c.Code != "" {
+			v.code = c.Code
+		}
+		if c.Message != "" {
+			// translated by its code, see errs.HTTPError.Localize
+			v.message = userMessage{text: c.Message}
+			v.override = true
+		}
+		if c.Field != "" {
+			v.field = c.Field
+		}
+		if c.Status != 0 {
+			status = c.Status
+		}
+	}
+
+	var fieldErrors []errs.FieldError
+	if v.field != "" {
+		fieldErrors = []errs.FieldError{{
+			Field: v.field,
+			Error: v.fieldError.text,
+			Key:   v.fieldError.key,
+		}}
+	}
+
+	return &errs.HTTPError{
+		Code:       v.code,
+		Message:    v.message.text,
+		Status:     status,
+		Override:   v.override,
+		Errors:     fieldErrors,
+		MessageKey: v.message.key,
+	}
+}
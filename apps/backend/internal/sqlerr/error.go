@@ -32,15 +32,61 @@ const (
 	// can be detected.
 	DeadlockDetected Code = "deadlock_detected"
 
+	// SerializationFailure is reported when a serializable or repeatable read
+	// transaction could not be serialized with concurrent transactions.
+	// The transaction can be retried.
+	SerializationFailure Code = "serialization_failure"
+
 	// TooManyConnections is reported when the database rejects a connection request
 	// due to reaching the maximum number of connections.
 	// This is different from blocking waiting on a connection pool.
 	TooManyConnections Code = "too_many_connections"
+
+	// LockNotAvailable is reported when a lock could not be acquired within
+	// lock_timeout, or immediately for NOWAIT.
+	LockNotAvailable Code = "lock_not_available"
+
+	// QueryCanceled is reported when a statement exceeded statement_timeout
+	// or was canceled by the client.
+	QueryCanceled Code = "query_canceled"
+
+	// InvalidTextRepresentation is reported when a value could not be parsed
+	// into the column type, such as a malformed UUID.
+	InvalidTextRepresentation Code = "invalid_text_representation"
+
+	// NumericValueOutOfRange is reported when a number does not fit the column type.
+	NumericValueOutOfRange Code = "numeric_value_out_of_range"
+
+	// StringDataRightTruncation is reported when a value is longer than the
+	// column allows.
+	StringDataRightTruncation Code = "string_data_right_truncation"
+
+	// UndefinedTable is reported when a statement references a table that
+	// does not exist, usually because a migration has not been applied.
+	UndefinedTable Code = "undefined_table"
+
+	// UndefinedColumn is reported when a statement references a column that
+	// does not exist.
+	UndefinedColumn Code = "undefined_column"
+
+	// AdminShutdown is reported when the server is shutting down and
+	// terminated the connection.
+	AdminShutdown Code = "admin_shutdown"
+
+	// CannotConnectNow is reported while the server is starting up or
+	// recovering and not yet accepting connections.
+	CannotConnectNow Code = "cannot_connect_now"
 )
 
 // MapCode maps an underlying database error to a Code.
 func MapCode(code string) Code {
 	switch code {
+	case "22001":
+		return StringDataRightTruncation
+	case "22003":
+		return NumericValueOutOfRange
+	case "22P02":
+		return InvalidTextRepresentation
 	case "23502":
 		return NotNullViolation
 	case "23503":
@@ -53,10 +99,24 @@ func MapCode(code string) Code {
 		return ExcludeViolation
 	case "25P02":
 		return TransactionFailed
+	case "40001":
+		return SerializationFailure
 	case "40P01":
 		return DeadlockDetected
+	case "42703":
+		return UndefinedColumn
+	case "42P01":
+		return UndefinedTable
 	case "53300":
 		return TooManyConnections
+	case "55P03":
+		return LockNotAvailable
+	case "57014":
+		return QueryCanceled
+	case "57P01":
+		return AdminShutdown
+	case "57P03":
+		return CannotConnectNow
 	default:
 		return Other
 	}
@@ -117,6 +177,9 @@ type Error struct {
 	// Message: the primary human-readable error message.
 	Message string
 
+	// Detail: an optional secondary message, such as the key of a unique violation.
+	Detail string
+
 	// SchemaName: if the error was associated with a specific database object,
 	// the name of the schema containing that object, if any.
 	SchemaName string
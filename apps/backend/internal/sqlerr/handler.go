@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 
@@ -25,6 +26,49 @@ func ErrCode(err error) Code {
 	return Other
 }
 
+// pgCode reports the Code of a *Error or *pgconn.PgError in the chain of err.
+func pgCode(err error) Code {
+	if code := ErrCode(err); code != Other {
+		return code
+	}
+
+	var pgerr *pgconn.PgError
+	if errors.As(err, &pgerr) {
+		return MapCode(pgerr.Code)
+	}
+	return Other
+}
+
+// IsRetryable reports whether err aborted a transaction only because of
+// concurrent transactions, so running the whole transaction again may succeed.
+func IsRetryable(err error) bool {
+	switch pgCode(err) {
+	case DeadlockDetected, SerializationFailure:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTransient reports whether err is caused by a temporary condition, such
+// as contention, timeouts, overload or a restarting server, so the operation
+// may succeed when tried again later. Every retryable error is transient.
+func IsTransient(err error) bool {
+	if IsRetryable(err) {
+		return true
+	}
+
+	switch pgCode(err) {
+	case LockNotAvailable, QueryCanceled, TooManyConnections, AdminShutdown, CannotConnectNow:
+		return true
+	}
+
+	// the request never reached the server, no connection could be made, or
+	// the connection was lost
+	var connectErr *pgconn.ConnectError
+	return pgconn.SafeToRetry(err) || pgconn.Timeout(err) || errors.As(err, &connectErr)
+}
+
 // ConvertPgError converts a pgconn.PgError to our custom Error type
 func ConvertPgError(src *pgconn.PgError) *Error {
 	return &Error{
@@ -32,6 +76,7 @@ func ConvertPgError(src *pgconn.PgError) *Error {
 		Severity:       MapSeverity(src.Severity),
 		DatabaseCode:   src.Code,
 		Message:        src.Message,
+		Detail:         src.Detail,
 		SchemaName:     src.SchemaName,
 		TableName:      src.TableName,
 		ColumnName:     src.ColumnName,
@@ -41,16 +86,37 @@ func ConvertPgError(src *pgconn.PgError) *Error {
 	}
 }
 
+// statusByCode lists the errors reported by their own code rather than one
+// derived from the table, with the status HandleError returns them with.
+var statusByCode = map[Code]int{
+	InvalidTextRepresentation: http.StatusBadRequest,
+	NumericValueOutOfRange:    http.StatusBadRequest,
+	StringDataRightTruncation: http.StatusRequestEntityTooLarge,
+	SerializationFailure:      http.StatusConflict,
+	DeadlockDetected:          http.StatusConflict,
+	LockNotAvailable:          http.StatusConflict,
+	QueryCanceled:             http.StatusGatewayTimeout,
+	AdminShutdown:             http.StatusServiceUnavailable,
+	CannotConnectNow:          http.StatusServiceUnavailable,
+	TooManyConnections:        http.StatusServiceUnavailable,
+	// the schema does not match the code, e.g. a migration was not applied
+	UndefinedTable:  http.StatusInternalServerError,
+	UndefinedColumn: http.StatusInternalServerError,
+}
+
+func init() {
+	for code, status := range statusByCode {
+		errs.Register(strings.ToUpper(string(code)), status, formatUserFriendlyMessage(&Error{Code: code}, "").text)
+	}
+}
+
 // generateErrorCode creates consistent error codes from database errors
 func generateErrorCode(tableName string, errType Code) string {
-	if tableName == "" {
-		tableName = "RECORD"
-	}
+	domain := errorDomain(tableName)
 
-	domain := strings.ToUpper(tableName)
-	// Singularize the table name
-	if strings.HasSuffix(domain, "S") && len(domain) > 1 {
-		domain = domain[:len(domain)-1]
+	// errors that are not about the table's data are reported by their own code
+	if _, ok := statusByCode[errType]; ok {
+		return strings.ToUpper(string(errType))
 	}
 
 	action := "ERROR"
@@ -63,34 +129,85 @@ func generateErrorCode(tableName string, errType Code) string {
 		action = "REQUIRED"
 	case CheckViolation:
 		action = "INVALID"
+	case ExcludeViolation:
+		action = "CONFLICT"
 	}
 
 	return fmt.Sprintf("%s_%s", domain, action)
 }
 
-// formatUserFriendlyMessage generates a user-friendly error message
-func formatUserFriendlyMessage(sqlErr *Error) string {
+// errorDomain is the prefix of the error codes derived from a table.
+func errorDomain(tableName string) string {
+	if tableName == "" {
+		tableName = "RECORD"
+	}
+	return strings.ToUpper(singularize(tableName))
+}
+
+// userMessage is an English message with the i18n key that translates it.
+type userMessage struct {
+	text string
+	key  string
+}
+
+// newUserMessage formats the English message. The table and column names it
+// interpolates are English, so they are not passed on to the translation,
+// which is phrased without them; the field errors still name the column.
+func newUserMessage(key, format string, args ...any) userMessage {
+	return userMessage{text: fmt.Sprintf(format, args...), key: key}
+}
+
+// formatUserFriendlyMessage generates a user-friendly error message.
+// uniqueColumn names the column of a unique violation, if known.
+// Messages of errors in statusByCode are translated by their code.
+func formatUserFriendlyMessage(sqlErr *Error, uniqueColumn string) userMessage {
 	entityName := getEntityName(sqlErr.TableName, sqlErr.ColumnName)
 
 	switch sqlErr.Code {
 	case ForeignKeyViolation:
-		return fmt.Sprintf("The referenced %s does not exist", entityName)
+		if table, ok := stillReferencedTable(sqlErr); ok {
+			return newUserMessage("db.foreign_key_in_use", "The %s is still in use", getEntityName(table, ""))
+		}
+		return newUserMessage("db.foreign_key_violation", "The referenced %s does not exist", entityName)
 	case UniqueViolation:
-		return fmt.Sprintf("A %s with this identifier already exists", entityName)
+		if uniqueColumn != "" {
+			return newUserMessage("db.unique_violation_field", "A %s with this %s already exists", entityName, humanizeText(uniqueColumn))
+		}
+		return newUserMessage("db.unique_violation", "A %s with this identifier already exists", entityName)
 	case NotNullViolation:
 		fieldName := humanizeText(sqlErr.ColumnName)
 		if fieldName == "" {
-			fieldName = "field"
+			return newUserMessage("db.not_null_violation_any", "The field is required")
 		}
-		return fmt.Sprintf("The %s is required", fieldName)
+		return newUserMessage("db.not_null_violation", "The %s is required", fieldName)
 	case CheckViolation:
 		fieldName := humanizeText(sqlErr.ColumnName)
 		if fieldName != "" {
-			return fmt.Sprintf("The %s value does not meet required conditions", fieldName)
+			return newUserMessage("db.check_violation", "The %s value does not meet required conditions", fieldName)
+		}
+		return newUserMessage("db.check_violation_any", "One or more values do not meet required conditions")
+	case ExcludeViolation:
+		return newUserMessage("db.exclude_violation", "The %[1]s conflicts with an existing %[1]s", entityName)
+	case InvalidTextRepresentation:
+		return userMessage{text: "One or more values have an invalid format"}
+	case NumericValueOutOfRange:
+		return userMessage{text: "A numeric value is out of range"}
+	case StringDataRightTruncation:
+		fieldName := humanizeText(sqlErr.ColumnName)
+		if fieldName != "" {
+			return newUserMessage("db.string_data_right_truncation", "The %s is too long", fieldName)
 		}
-		return "One or more values do not meet required conditions"
+		return userMessage{text: "A value is too long"}
+	case SerializationFailure, DeadlockDetected, LockNotAvailable:
+		return userMessage{text: "The request conflicted with a concurrent update, please try again"}
+	case QueryCanceled:
+		return userMessage{text: "The request took too long to complete"}
+	case AdminShutdown, CannotConnectNow, TooManyConnections:
+		return userMessage{text: "The service is temporarily unavailable, please try again later"}
+	case UndefinedTable, UndefinedColumn:
+		return userMessage{text: http.StatusText(http.StatusInternalServerError)}
 	default:
-		return "An error occurred while processing your request"
+		return userMessage{text: "An error occurred while processing your request"}
 	}
 }
 
@@ -104,18 +221,49 @@ func getEntityName(tableName, columnName string) string {
 
 	// Second priority: table name (fallback option)
 	if tableName != "" {
-		// Use singular form
-		entity := tableName
-		if strings.HasSuffix(entity, "s") && len(entity) > 1 {
-			entity = entity[:len(entity)-1]
-		}
-		return humanizeText(entity)
+		return humanizeText(singularize(tableName))
 	}
 
 	// Default fallback
 	return "record"
 }
 
+// singularize turns a plural table name into its singular form, so that
+// "addresses" becomes "address" rather than "addresse" and "statuses"
+// "status" rather than "statuse". Irregular names should be covered by
+// RegisterConstraint instead.
+func singularize(word string) string {
+	lower := strings.ToLower(word)
+
+	switch {
+	case len(lower) > 3 && strings.HasSuffix(lower, "ies"):
+		return word[:len(word)-3] + matchCase(word[len(word)-3:], "y")
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"), isUsPlural(lower):
+		return word[:len(word)-2]
+	case len(lower) > 1 && strings.HasSuffix(lower, "s") && !strings.HasSuffix(lower, "ss") &&
+		!strings.HasSuffix(lower, "us"):
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
+
+// isUsPlural reports whether word is the plural of a word ending in "us",
+// such as "statuses" or "bonuses", as opposed to "houses" or "causes".
+func isUsPlural(word string) bool {
+	stem, ok := strings.CutSuffix(word, "uses")
+	return ok && stem != "" && !strings.ContainsAny(stem[len(stem)-1:], "aeiou")
+}
+
+// matchCase returns replacement in upper case when suffix is upper case.
+func matchCase(suffix, replacement string) string {
+	if suffix == strings.ToUpper(suffix) {
+		return strings.ToUpper(replacement)
+	}
+	return replacement
+}
+
 // humanizeText converts snake_case to human-readable text
 func humanizeText(text string) string {
 	if text == "" {
@@ -124,8 +272,50 @@ func humanizeText(text string) string {
 	return cases.Title(language.English).String(strings.ReplaceAll(text, "_", " "))
 }
 
-// extractColumnForUniqueViolation gets field name from unique constraint
-func extractColumnForUniqueViolation(constraintName string) string {
+// keyDetailRegex matches the detail of unique and foreign key violations,
+// e.g. `Key (email)=(a@b.c) already exists.`
+var keyDetailRegex = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// extractColumnFromDetail gets the violated column from the error detail.
+// Keys over several columns report no column.
+func extractColumnFromDetail(detail string) (string, bool) {
+	matches := keyDetailRegex.FindStringSubmatch(detail)
+	if len(matches) < 2 {
+		return "", false
+	}
+	if strings.Contains(matches[1], ",") {
+		return "", true
+	}
+	return strings.Trim(matches[1], `"`), true
+}
+
+// deleteViolationRegex matches the message of a foreign key violation raised
+// by deleting or updating a referenced row, which names that row's table,
+// e.g. `update or delete on table "users" violates foreign key constraint ...`
+var deleteViolationRegex = regexp.MustCompile(`^update or delete on table "([^"]+)"`)
+
+// stillReferencedTable reports whether a foreign key violation was raised by
+// deleting or updating a row that other rows still reference, rather than by
+// inserting or updating a row that references a missing one, and returns the
+// table of the referenced row.
+func stillReferencedTable(sqlErr *Error) (string, bool) {
+	if !strings.Contains(sqlErr.Detail, "is still referenced") {
+		return "", false
+	}
+	if matches := deleteViolationRegex.FindStringSubmatch(sqlErr.Message); len(matches) > 1 {
+		return matches[1], true
+	}
+	return "", true
+}
+
+// extractColumnForUniqueViolation gets the violated column from the error
+// detail, or guesses it from the constraint name.
+func extractColumnForUniqueViolation(sqlErr *Error) string {
+	if column, ok := extractColumnFromDetail(sqlErr.Detail); ok {
+		return column
+	}
+
+	constraintName := sqlErr.ConstraintName
 	if constraintName == "" {
 		return ""
 	}
@@ -163,30 +353,78 @@ func HandleError(err error) error {
 
 		// Generate an appropriate error code and message
 		errorCode := generateErrorCode(sqlErr.TableName, sqlErr.Code)
-		userMessage := formatUserFriendlyMessage(sqlErr)
+		var uniqueColumn string
+		if sqlErr.Code == UniqueViolation {
+			uniqueColumn = extractColumnForUniqueViolation(sqlErr)
+		}
+		message := formatUserFriendlyMessage(sqlErr, uniqueColumn)
 
 		switch sqlErr.Code {
+		// registered constraints take precedence over the derived errors
 		case ForeignKeyViolation:
-			return errs.NewBadRequestError(userMessage, false, &errorCode, nil, nil)
+			// the key belongs to the referenced row, not to the request's fields
+			if table, ok := stillReferencedTable(sqlErr); ok {
+				return constraintError(sqlErr, violation{
+					code: errorDomain(table) + "_IN_USE", message: message, override: true,
+					status: http.StatusConflict,
+				})
+			}
+
+			columnName, _ := extractColumnFromDetail(sqlErr.Detail)
+			return constraintError(sqlErr, violation{
+				code: errorCode, message: message,
+				field: strings.ToLower(columnName), fieldError: newUserMessage("db.field_not_found", "does not exist"),
+			})
 
 		case UniqueViolation:
-			columnName := extractColumnForUniqueViolation(sqlErr.ConstraintName)
-			if columnName != "" {
-				userMessage = strings.ReplaceAll(userMessage, "identifier", humanizeText(columnName))
-			}
-			return errs.NewBadRequestError(userMessage, true, &errorCode, nil, nil)
+			return constraintError(sqlErr, violation{
+				code: errorCode, message: message, override: true,
+				field: strings.ToLower(uniqueColumn), fieldError: newUserMessage("db.field_exists", "already exists"),
+			})
 
 		case NotNullViolation:
-			fieldErrors := []errs.FieldError{
-				{
-					Field: strings.ToLower(sqlErr.ColumnName),
-					Error: "is required",
-				},
-			}
-			return errs.NewBadRequestError(userMessage, true, &errorCode, fieldErrors, nil)
+			return constraintError(sqlErr, violation{
+				code: errorCode, message: message, override: true,
+				field: strings.ToLower(sqlErr.ColumnName), fieldError: newUserMessage("validation.required", "is required"),
+			})
 
 		case CheckViolation:
-			return errs.NewBadRequestError(userMessage, true, &errorCode, nil, nil)
+			return constraintError(sqlErr, violation{
+				code: errorCode, message: message, override: true,
+				field: strings.ToLower(sqlErr.ColumnName), fieldError: newUserMessage("db.field_invalid", "is invalid"),
+			})
+
+		case ExcludeViolation:
+			return constraintError(sqlErr, violation{
+				code: errorCode, message: message, override: true,
+				fieldError: newUserMessage("db.field_conflict", "conflicts with an existing record"),
+			})
+
+		case InvalidTextRepresentation, NumericValueOutOfRange:
+			return errs.NewBadRequestError(message.text, true, &errorCode, nil, nil)
+
+		case StringDataRightTruncation:
+			var fieldErrors []errs.FieldError
+			if sqlErr.ColumnName != "" {
+				fieldErrors = []errs.FieldError{{Field: strings.ToLower(sqlErr.ColumnName), Error: "is too long", Key: "db.field_too_long"}}
+			}
+			httpErr := errs.NewPayloadTooLargeError(message.text, true, &errorCode).WithMessageKey(message.key)
+			httpErr.Errors = fieldErrors
+			return httpErr
+
+		case SerializationFailure, DeadlockDetected, LockNotAvailable:
+			return errs.NewConflictError(message.text, true, &errorCode)
+
+		case QueryCanceled:
+			return errs.NewGatewayTimeoutError(message.text, true, &errorCode)
+
+		case AdminShutdown, CannotConnectNow, TooManyConnections:
+			return errs.NewServiceUnavailableError(message.text, true, &errorCode)
+
+		case UndefinedTable, UndefinedColumn:
+			httpErr := errs.NewInternalServerError()
+			httpErr.Code = errorCode
+			return httpErr
 
 		default:
 			return errs.NewInternalServerError()
@@ -202,9 +440,9 @@ func HandleError(err error) error {
 			table := strings.Split(strings.Split(errMsg, tablePrefix)[1], ":")[0]
 			entityName := getEntityName(table, "")
 			return errs.NewNotFoundError(fmt.Sprintf("%s not found",
-				entityName), true, nil)
+				entityName), true, nil).WithMessageKey("db.not_found")
 		}
-		return errs.NewNotFoundError("Resource not found", false, nil)
+		return errs.NewNotFoundError("Resource not found", false, nil).WithMessageKey("db.resource_not_found")
 	}
 
 	return errs.NewInternalServerError()
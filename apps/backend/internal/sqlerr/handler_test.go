@@ -0,0 +1,266 @@
+package sqlerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/C0deNe0/go-boiler/internal/errs"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestMapCode(t *testing.T) {
+	tests := []struct {
+		sqlState string
+		want     Code
+	}{
+		{"22001", StringDataRightTruncation},
+		{"22003", NumericValueOutOfRange},
+		{"22P02", InvalidTextRepresentation},
+		{"23502", NotNullViolation},
+		{"23503", ForeignKeyViolation},
+		{"23505", UniqueViolation},
+		{"23514", CheckViolation},
+		{"23P01", ExcludeViolation},
+		{"25P02", TransactionFailed},
+		{"40001", SerializationFailure},
+		{"40P01", DeadlockDetected},
+		{"42703", UndefinedColumn},
+		{"42P01", UndefinedTable},
+		{"53300", TooManyConnections},
+		{"55P03", LockNotAvailable},
+		{"57014", QueryCanceled},
+		{"57P01", AdminShutdown},
+		{"57P03", CannotConnectNow},
+		{"42601", Other},
+		{"", Other},
+	}
+
+	for _, tt := range tests {
+		if got := MapCode(tt.sqlState); got != tt.want {
+			t.Errorf("MapCode(%q) = %s, want %s", tt.sqlState, got, tt.want)
+		}
+	}
+}
+
+func TestIsRetryableAndIsTransient(t *testing.T) {
+	wrap := func(sqlState string) error {
+		return fmt.Errorf("query failed: %w", &pgconn.PgError{Code: sqlState})
+	}
+
+	tests := []struct {
+		name          string
+		err           error
+		wantRetryable bool
+		wantTransient bool
+	}{
+		{name: "nil", err: nil},
+		{name: "plain error", err: errors.New("boom")},
+		{name: "serialization failure", err: wrap("40001"), wantRetryable: true, wantTransient: true},
+		{name: "deadlock", err: wrap("40P01"), wantRetryable: true, wantTransient: true},
+		{name: "converted error", err: ConvertPgError(&pgconn.PgError{Code: "40001"}), wantRetryable: true, wantTransient: true},
+		{name: "lock not available", err: wrap("55P03"), wantTransient: true},
+		{name: "query canceled", err: wrap("57014"), wantTransient: true},
+		{name: "too many connections", err: wrap("53300"), wantTransient: true},
+		{name: "admin shutdown", err: wrap("57P01"), wantTransient: true},
+		{name: "cannot connect now", err: wrap("57P03"), wantTransient: true},
+		{name: "unique violation", err: wrap("23505")},
+		{name: "undefined table", err: wrap("42P01")},
+		{name: "connect error", err: fmt.Errorf("acquire: %w", &pgconn.ConnectError{}), wantTransient: true},
+		{name: "context canceled", err: fmt.Errorf("query: %w", context.Canceled)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable() = %t, want %t", got, tt.wantRetryable)
+			}
+			if got := IsTransient(tt.err); got != tt.wantTransient {
+				t.Errorf("IsTransient() = %t, want %t", got, tt.wantTransient)
+			}
+		})
+	}
+}
+
+func TestSingularize(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"users", "user"},
+		{"USERS", "USER"},
+		{"categories", "category"},
+		{"CATEGORIES", "CATEGORY"},
+		{"addresses", "address"},
+		{"statuses", "status"},
+		{"STATUSES", "STATUS"},
+		{"bonuses", "bonus"},
+		{"houses", "house"},
+		{"causes", "cause"},
+		{"courses", "course"},
+		{"boxes", "box"},
+		{"matches", "match"},
+		{"wishes", "wish"},
+		{"status", "status"},
+		{"address", "address"},
+		{"user_roles", "user_role"},
+		{"data", "data"},
+		{"s", "s"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := singularize(tt.word); got != tt.want {
+			t.Errorf("singularize(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestExtractColumnFromDetail(t *testing.T) {
+	tests := []struct {
+		detail     string
+		wantColumn string
+		wantOK     bool
+	}{
+		{detail: "Key (email)=(a@b.c) already exists.", wantColumn: "email", wantOK: true},
+		{detail: `Key ("Email")=(a@b.c) already exists.`, wantColumn: "Email", wantOK: true},
+		{detail: `Key (user_id)=(42) is not present in table "users".`, wantColumn: "user_id", wantOK: true},
+		{detail: `Key (id)=(42) is still referenced from table "orders".`, wantColumn: "id", wantOK: true},
+		{detail: "Key (tenant_id, email)=(1, a@b.c) already exists.", wantOK: true},
+		{detail: "Failing row contains (1, -5)."},
+		{detail: ""},
+	}
+
+	for _, tt := range tests {
+		column, ok := extractColumnFromDetail(tt.detail)
+		if column != tt.wantColumn || ok != tt.wantOK {
+			t.Errorf("extractColumnFromDetail(%q) = %q, %t, want %q, %t", tt.detail, column, ok, tt.wantColumn, tt.wantOK)
+		}
+	}
+}
+
+func TestHandleError(t *testing.T) {
+	RegisterConstraint("handler_test_payments", "positive_amount", Constraint{
+		Code:    "HANDLER_TEST_AMOUNT_NOT_POSITIVE",
+		Message: "The amount must be positive",
+		Field:   "amount",
+	})
+
+	tests := []struct {
+		name       string
+		err        *pgconn.PgError
+		wantCode   string
+		wantStatus int
+		wantFields []string
+	}{
+		{
+			name: "unique violation",
+			err: &pgconn.PgError{
+				Code: "23505", TableName: "users", ConstraintName: "users_email_key",
+				Detail: "Key (email)=(a@b.c) already exists.",
+			},
+			wantCode:   "USER_ALREADY_EXISTS",
+			wantStatus: http.StatusConflict,
+			wantFields: []string{"email: already exists"},
+		},
+		{
+			name: "foreign key violation on insert",
+			err: &pgconn.PgError{
+				Code: "23503", TableName: "orders", ConstraintName: "orders_user_id_fkey",
+				Message: `insert or update on table "orders" violates foreign key constraint "orders_user_id_fkey"`,
+				Detail:  `Key (user_id)=(42) is not present in table "users".`,
+			},
+			wantCode:   "ORDER_NOT_FOUND",
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"user_id: does not exist"},
+		},
+		{
+			name: "foreign key violation on delete",
+			err: &pgconn.PgError{
+				Code: "23503", TableName: "orders", ConstraintName: "orders_user_id_fkey",
+				Message: `update or delete on table "users" violates foreign key constraint "orders_user_id_fkey" on table "orders"`,
+				Detail:  `Key (id)=(42) is still referenced from table "orders".`,
+			},
+			wantCode:   "USER_IN_USE",
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "not null violation",
+			err:        &pgconn.PgError{Code: "23502", TableName: "users", ColumnName: "name"},
+			wantCode:   "USER_REQUIRED",
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"name: is required"},
+		},
+		{
+			name:       "registered constraint",
+			err:        &pgconn.PgError{Code: "23514", TableName: "handler_test_payments", ConstraintName: "positive_amount"},
+			wantCode:   "HANDLER_TEST_AMOUNT_NOT_POSITIVE",
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"amount: is invalid"},
+		},
+		{
+			name:       "same constraint name on another table",
+			err:        &pgconn.PgError{Code: "23514", TableName: "handler_test_refunds", ConstraintName: "positive_amount"},
+			wantCode:   "HANDLER_TEST_REFUND_INVALID",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "serialization failure",
+			err:        &pgconn.PgError{Code: "40001"},
+			wantCode:   "SERIALIZATION_FAILURE",
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "query canceled",
+			err:        &pgconn.PgError{Code: "57014"},
+			wantCode:   "QUERY_CANCELED",
+			wantStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:       "undefined table",
+			err:        &pgconn.PgError{Code: "42P01", TableName: "job_outbox"},
+			wantCode:   "UNDEFINED_TABLE",
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "undefined column",
+			err:        &pgconn.PgError{Code: "42703", TableName: "users"},
+			wantCode:   "UNDEFINED_COLUMN",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var httpErr *errs.HTTPError
+			if !errors.As(HandleError(fmt.Errorf("query: %w", tt.err)), &httpErr) {
+				t.Fatal("HandleError did not return an *errs.HTTPError")
+			}
+
+			if httpErr.Code != tt.wantCode || httpErr.Status != tt.wantStatus {
+				t.Errorf("HandleError() = %s %d, want %s %d", httpErr.Code, httpErr.Status, tt.wantCode, tt.wantStatus)
+			}
+			var fields []string
+			for _, fieldError := range httpErr.Errors {
+				fields = append(fields, fieldError.Field+": "+fieldError.Error)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("field errors = %q, want %q", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestRegisterConstraintTwicePanics(t *testing.T) {
+	RegisterConstraint("handler_test_accounts", "balance_check", Constraint{Code: "HANDLER_TEST_BALANCE"})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a constraint twice did not panic")
+		}
+	}()
+	RegisterConstraint("handler_test_accounts", "balance_check", Constraint{Code: "HANDLER_TEST_BALANCE_AGAIN"})
+}
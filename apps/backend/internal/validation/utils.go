@@ -18,6 +18,9 @@ type Validatable interface {
 type CustomValidationError struct {
 	Field   string
 	Message string
+	// Key and Args translate Message; see errs.FieldError
+	Key  string
+	Args []any
 }
 
 type CustomValidationErrors []CustomValidationError
@@ -33,12 +36,19 @@ func BindAndValidate(c echo.Context, payload Validatable) error {
 	}
 
 	if msg, fieldErrors := validateStruct(payload); fieldErrors != nil {
-		return errs.NewBadRequestError(msg, true, nil, fieldErrors, nil)
+		return errs.NewBadRequestError(msg, true, nil, fieldErrors, nil).WithMessageKey(validationFailedKey)
 	}
 
 	return nil
 }
 
+// NewValidationError converts validator.ValidationErrors or
+// CustomValidationErrors into a bad request with field errors.
+func NewValidationError(err error) error {
+	msg, fieldErrors := extractValidationErrors(err)
+	return errs.NewBadRequestError(msg, true, nil, fieldErrors, nil).WithMessageKey(validationFailedKey)
+}
+
 func validateStruct(v Validatable) (string, []errs.FieldError) {
 	if err := v.Validate(); err != nil {
 		return extractValidationErrors(err)
@@ -55,6 +65,8 @@ func extractValidationErrors(err error) (string, []errs.FieldError) {
 			fieldErrors = append(fieldErrors, errs.FieldError{
 				Field: err.Field,
 				Error: err.Message,
+				Key:   err.Key,
+				Args:  err.Args,
 			})
 		}
 	}
@@ -62,18 +74,26 @@ func extractValidationErrors(err error) (string, []errs.FieldError) {
 	for _, err := range validationErrors {
 		field := strings.ToLower(err.Field())
 		var msg string
+		// translations are keyed by tag and take the tag parameter, if any
+		key := "validation." + err.Tag()
+		var args []any
+		if err.Param() != "" {
+			args = []any{err.Param()}
+		}
 
 		switch err.Tag() {
 		case "required":
 			msg = "is required"
 		case "min":
 			if err.Type().Kind() == reflect.String {
+				key = "validation.min_length"
 				msg = fmt.Sprintf("must be at least %s characters", err.Param())
 			} else {
 				msg = fmt.Sprintf("must be at least %s", err.Param())
 			}
 		case "max":
 			if err.Type().Kind() == reflect.String {
+				key = "validation.max_length"
 				msg = fmt.Sprintf("must not exceed %s characters", err.Param())
 			} else {
 				msg = fmt.Sprintf("must not exceed %s", err.Param())
@@ -91,6 +111,7 @@ func extractValidationErrors(err error) (string, []errs.FieldError) {
 		case "dive":
 			msg = "some items are invalid"
 		default:
+			key = ""
 			if err.Param() != "" {
 				msg = fmt.Sprintf("%s: %s:%s", field, err.Tag(), err.Param())
 			} else {
@@ -101,12 +122,16 @@ func extractValidationErrors(err error) (string, []errs.FieldError) {
 		fieldErrors = append(fieldErrors, errs.FieldError{
 			Field: strings.ToLower(err.Field()),
 			Error: msg,
+			Key:   key,
+			Args:  args,
 		})
 	}
 
 	return "Validation failed", fieldErrors
 }
 
+const validationFailedKey = "validation.failed"
+
 var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 
 func IsValidUUID(uuid string) bool {
@@ -14,30 +14,49 @@ import {
   Tailwind,
 } from "@react-email/components";
 
+// The backend renders the exported HTML with Go templates and passes every
+// string already translated into the recipient's language, so the defaults
+// are template placeholders.
 interface WelcomeEmailProps {
-  userFirstName: string;
+  lang: string;
+  preview: string;
+  heading: string;
+  greeting: string;
+  body: string;
+  getStarted: string;
+  supportPrefix: string;
+  supportLink: string;
+  copyright: string;
 }
 
 export const WelcomeEmail = ({
-  userFirstName = "{{.UserFirstName}}",
+  lang = "{{.Lang}}",
+  preview = "{{.Preview}}",
+  heading = "{{.Heading}}",
+  greeting = "{{.Greeting}}",
+  body = "{{.Body}}",
+  getStarted = "{{.GetStarted}}",
+  supportPrefix = "{{.SupportPrefix}}",
+  supportLink = "{{.SupportLink}}",
+  copyright = "{{.Copyright}}",
 }: WelcomeEmailProps) => {
   return (
-    <Html>
+    <Html lang={lang}>
       <Head />
-      <Preview>Welcome to Boilerplate</Preview>
+      <Preview>{preview}</Preview>
       <Tailwind>
         <Body className="bg-gray-100 font-sans">
           <Container className="bg-white p-8 rounded-lg shadow-sm my-10 mx-auto max-w-[600px]">
             <Heading className="text-2xl font-bold text-gray-800 mt-4">
-              Welcome to Boilerplate!
+              {heading}
             </Heading>
 
             <Section>
               <Text className="text-gray-700 text-base">
-                Hi {userFirstName},
+                {greeting}
               </Text>
               <Text className="text-gray-700 text-base">
-                Thank you for joining!
+                {body}
               </Text>
             </Section>
 
@@ -46,7 +65,7 @@ export const WelcomeEmail = ({
                 className="bg-orange-600 hover:bg-orange-700 text-white font-medium rounded-md px-6 py-3"
                 href={`/dashboard`}
               >
-                Get Started
+                {getStarted}
               </Button>
             </Section>
 
@@ -54,9 +73,9 @@ export const WelcomeEmail = ({
 
             <Section>
               <Text className="text-gray-600 text-sm">
-                If you have any questions, feel free to{" "}
+                {supportPrefix}{" "}
                 <Link href={`/support`} className="text-orange-600 underline">
-                  contact our support team
+                  {supportLink}
                 </Link>
                 .
               </Text>
@@ -64,7 +83,7 @@ export const WelcomeEmail = ({
 
             <Section className="mt-8 text-center">
               <Text className="text-gray-500 text-xs">
-                © {new Date().getFullYear()} Alfred. All rights reserved.
+                © {new Date().getFullYear()} Alfred. {copyright}
               </Text>
               <Text className="text-gray-500 text-xs">
                 123 Project Street, Suite 100, San Francisco, CA 94103
@@ -78,7 +97,15 @@ export const WelcomeEmail = ({
 };
 
 WelcomeEmail.PreviewProps = {
-  userFirstName: "John",
+  lang: "en",
+  preview: "Welcome to Boilerplate",
+  heading: "Welcome to Boilerplate!",
+  greeting: "Hi John,",
+  body: "Thank you for joining!",
+  getStarted: "Get Started",
+  supportPrefix: "If you have any questions, feel free to",
+  supportLink: "contact our support team",
+  copyright: "All rights reserved.",
 };
 
 export default WelcomeEmail;
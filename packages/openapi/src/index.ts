@@ -5,6 +5,7 @@ extendZodWithOpenApi(z);
 import { generateOpenApi } from "@ts-rest/open-api";
 
 import { apiContract } from "./contracts/index.js";
+import errorCodes from "./error-codes.json" with { type: "json" };
 
 type SecurityRequirementObject = {
   [key: string]: string[];
@@ -67,6 +68,52 @@ export const OpenAPI = Object.assign(
           in: "header",
         },
       },
+      schemas: {
+        // known codes are generated by `task errors:export` in apps/backend;
+        // the set is open, so clients must handle codes not listed here
+        ErrorCode: {
+          type: "string",
+          description:
+            "Stable application error code; switch on it rather than the message. " +
+            "The known codes are listed in x-extensible-enum. Database errors also " +
+            "return codes derived from the table, such as USER_ALREADY_EXISTS, " +
+            "<ENTITY>_NOT_FOUND, _REQUIRED, _INVALID and _CONFLICT, and domains " +
+            "may add codes for their constraints, so the set is open.",
+          "x-extensible-enum": errorCodes.map((entry) => entry.code),
+        },
+        ErrorResponse: {
+          type: "object",
+          properties: {
+            code: { $ref: "#/components/schemas/ErrorCode" },
+            message: { type: "string" },
+            status: { type: "integer" },
+            override: { type: "boolean" },
+            errors: {
+              type: "array",
+              nullable: true,
+              items: {
+                type: "object",
+                properties: {
+                  field: { type: "string" },
+                  error: { type: "string" },
+                },
+                required: ["field", "error"],
+              },
+            },
+            action: {
+              type: "object",
+              nullable: true,
+              properties: {
+                type: { type: "string" },
+                message: { type: "string" },
+                value: { type: "string" },
+              },
+              required: ["type", "message", "value"],
+            },
+          },
+          required: ["code", "message", "status", "override"],
+        },
+      },
     },
   }
 );